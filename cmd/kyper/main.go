@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/bitfootco/kyper-cli/internal/cmd"
@@ -9,6 +10,10 @@ import (
 
 func main() {
 	if err := cmd.Execute(); err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		ui.PrintError(err.Error())
 		os.Exit(1)
 	}
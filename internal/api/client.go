@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 )
 
@@ -73,6 +74,20 @@ type BuildLog struct {
 	Complete bool   `json:"complete"`
 }
 
+// Capabilities describes optional features the server supports.
+type Capabilities struct {
+	ArchiveFormats []string         `json:"archive_formats"`
+	TestDeploy     TestDeployLimits `json:"test_deploy"`
+}
+
+// TestDeployLimits bounds test deploy lifetimes, in seconds. Zero means the
+// server didn't advertise that limit.
+type TestDeployLimits struct {
+	DefaultTTLSeconds int `json:"default_ttl_seconds"`
+	MinTTLSeconds     int `json:"min_ttl_seconds"`
+	MaxTTLSeconds     int `json:"max_ttl_seconds"`
+}
+
 type MessageResponse struct {
 	ID      int    `json:"id"`
 	Message string `json:"message"`
@@ -172,6 +187,42 @@ func parseAPIError(statusCode int, body []byte) *APIError {
 	return apiErr
 }
 
+// writeArchivePart adds the source archive to a multipart form. Zip uploads
+// use the source_zip field; other formats use source_archive plus an
+// archive_format field naming the encoding.
+func writeArchivePart(writer *multipart.Writer, archivePath, archiveFormat string) error {
+	field := "source_zip"
+	if archiveFormat != "" && archiveFormat != "zip" {
+		field = "source_archive"
+		if err := writer.WriteField("archive_format", archiveFormat); err != nil {
+			return fmt.Errorf("writing archive_format field: %w", err)
+		}
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	part, err := writer.CreateFormFile(field, filepath.Base(archivePath))
+	if err != nil {
+		return fmt.Errorf("creating form file: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("copying archive to form: %w", err)
+	}
+	return nil
+}
+
+// Capabilities
+
+func (c *Client) GetCapabilities() (*Capabilities, error) {
+	var caps Capabilities
+	err := c.doJSON("GET", "/api/v1/capabilities", nil, &caps)
+	return &caps, err
+}
+
 // Device Auth
 
 func (c *Client) DeviceAuthorize() (*DeviceGrant, error) {
@@ -222,7 +273,10 @@ func (c *Client) GetAppStatus(slug string) (*AppStatus, error) {
 
 // Versions
 
-func (c *Client) CreateVersion(slug, kyperYml, zipPath string) (*VersionResponse, error) {
+// CreateVersion uploads a source archive and kyper.yml as a new version.
+// archiveFormat is sent only when it isn't "zip", so older servers keep
+// receiving the original source_zip field.
+func (c *Client) CreateVersion(slug, kyperYml, archivePath, archiveFormat string) (*VersionResponse, error) {
 	// Build multipart form
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -232,19 +286,8 @@ func (c *Client) CreateVersion(slug, kyperYml, zipPath string) (*VersionResponse
 		return nil, fmt.Errorf("writing kyper_yml field: %w", err)
 	}
 
-	// Add source_zip file
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return nil, fmt.Errorf("opening zip file: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
-	part, err := writer.CreateFormFile("source_zip", filepath.Base(zipPath))
-	if err != nil {
-		return nil, fmt.Errorf("creating form file: %w", err)
-	}
-	if _, err := io.Copy(part, file); err != nil {
-		return nil, fmt.Errorf("copying zip to form: %w", err)
+	if err := writeArchivePart(writer, archivePath, archiveFormat); err != nil {
+		return nil, err
 	}
 	if err := writer.Close(); err != nil {
 		return nil, fmt.Errorf("finalizing multipart form: %w", err)
@@ -330,7 +373,58 @@ type TestDeployStatus struct {
 	Deployment  *TestDeployment `json:"deployment"`
 }
 
-func (c *Client) CreateTestDeploy(slug, kyperYml, zipPath string, envVars map[string]string) (*TestDeployResponse, error) {
+// RuntimeLogLine is one line of stdout/stderr from a running process.
+type RuntimeLogLine struct {
+	Timestamp string `json:"timestamp"`
+	Process   string `json:"process"`
+	Stream    string `json:"stream"`
+	Message   string `json:"message"`
+}
+
+// RuntimeLogs is a page of runtime log lines. Pass Cursor back to fetch
+// lines written since.
+type RuntimeLogs struct {
+	Lines  []RuntimeLogLine `json:"lines"`
+	Cursor int              `json:"cursor"`
+}
+
+// RuntimeLogOptions filters GetTestDeployLogs. Empty fields are omitted.
+type RuntimeLogOptions struct {
+	Process string
+	Since   time.Time
+	Cursor  int
+}
+
+// TestDeployOptions are the optional settings of a test deploy. Zero values
+// leave the server defaults in place; an empty Name targets the app's
+// default (unnamed) test deploy.
+type TestDeployOptions struct {
+	Name    string
+	EnvVars map[string]string
+	TTL     time.Duration
+}
+
+// TestDeploySummary is one entry in an app's list of active test deploys.
+// Name is empty for the default test deploy.
+type TestDeploySummary struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	BuildStatus string `json:"build_status"`
+	URL         string `json:"url"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// testDeployPath returns the API path of one of an app's test deploys. The
+// default deploy keeps its original route; named deploys live under
+// test_deploys.
+func testDeployPath(slug, name string) string {
+	if name == "" {
+		return "/api/v1/apps/" + slug + "/test_deploy"
+	}
+	return "/api/v1/apps/" + slug + "/test_deploys/" + url.PathEscape(name)
+}
+
+func (c *Client) CreateTestDeploy(slug, kyperYml, archivePath, archiveFormat string, opts TestDeployOptions) (*TestDeployResponse, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
@@ -338,8 +432,14 @@ func (c *Client) CreateTestDeploy(slug, kyperYml, zipPath string, envVars map[st
 		return nil, fmt.Errorf("writing kyper_yml field: %w", err)
 	}
 
-	if len(envVars) > 0 {
-		jsonVars, err := json.Marshal(envVars)
+	if opts.TTL > 0 {
+		if err := writer.WriteField("ttl_seconds", strconv.Itoa(int(opts.TTL.Seconds()))); err != nil {
+			return nil, fmt.Errorf("writing ttl_seconds field: %w", err)
+		}
+	}
+
+	if len(opts.EnvVars) > 0 {
+		jsonVars, err := json.Marshal(opts.EnvVars)
 		if err != nil {
 			return nil, fmt.Errorf("encoding env vars: %w", err)
 		}
@@ -348,24 +448,14 @@ func (c *Client) CreateTestDeploy(slug, kyperYml, zipPath string, envVars map[st
 		}
 	}
 
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return nil, fmt.Errorf("opening zip file: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
-	part, err := writer.CreateFormFile("source_zip", filepath.Base(zipPath))
-	if err != nil {
-		return nil, fmt.Errorf("creating form file: %w", err)
-	}
-	if _, err := io.Copy(part, file); err != nil {
-		return nil, fmt.Errorf("copying zip to form: %w", err)
+	if err := writeArchivePart(writer, archivePath, archiveFormat); err != nil {
+		return nil, err
 	}
 	if err := writer.Close(); err != nil {
 		return nil, fmt.Errorf("finalizing multipart form: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.BaseURL+"/api/v1/apps/"+slug+"/test_deploy", body)
+	req, err := http.NewRequest("POST", c.BaseURL+testDeployPath(slug, opts.Name), body)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -394,15 +484,52 @@ func (c *Client) CreateTestDeploy(slug, kyperYml, zipPath string, envVars map[st
 	return &tr, nil
 }
 
-func (c *Client) GetTestDeploy(slug string, provisionLogCursor int) (*TestDeployStatus, error) {
+func (c *Client) GetTestDeploy(slug, name string, provisionLogCursor int) (*TestDeployStatus, error) {
 	var status TestDeployStatus
-	path := fmt.Sprintf("/api/v1/apps/%s/test_deploy?provision_log_cursor=%d", slug, provisionLogCursor)
+	path := fmt.Sprintf("%s?provision_log_cursor=%d", testDeployPath(slug, name), provisionLogCursor)
 	err := c.doJSON("GET", path, nil, &status)
 	return &status, err
 }
 
-func (c *Client) DeleteTestDeploy(slug string) (*MessageResponse, error) {
+func (c *Client) DeleteTestDeploy(slug, name string) (*MessageResponse, error) {
 	var resp MessageResponse
-	err := c.doJSON("DELETE", "/api/v1/apps/"+slug+"/test_deploy", nil, &resp)
+	err := c.doJSON("DELETE", testDeployPath(slug, name), nil, &resp)
 	return &resp, err
 }
+
+func (c *Client) ListTestDeploys(slug string) ([]TestDeploySummary, error) {
+	var resp struct {
+		TestDeploys []TestDeploySummary `json:"test_deploys"`
+	}
+	err := c.doJSON("GET", "/api/v1/apps/"+slug+"/test_deploys", nil, &resp)
+	return resp.TestDeploys, err
+}
+
+// ExtendTestDeploy pushes the active test deploy's expiry back by the given
+// duration and returns the updated deployment.
+func (c *Client) ExtendTestDeploy(slug, name string, by time.Duration) (*TestDeployment, error) {
+	var d TestDeployment
+	body := map[string]int{"extend_by_seconds": int(by.Seconds())}
+	err := c.doJSON("POST", testDeployPath(slug, name)+"/extend", body, &d)
+	return &d, err
+}
+
+func (c *Client) GetTestDeployLogs(slug, name string, opts RuntimeLogOptions) (*RuntimeLogs, error) {
+	q := url.Values{}
+	if opts.Process != "" {
+		q.Set("process", opts.Process)
+	}
+	if !opts.Since.IsZero() {
+		q.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.Cursor > 0 {
+		q.Set("cursor", fmt.Sprintf("%d", opts.Cursor))
+	}
+	path := testDeployPath(slug, name) + "/logs"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var logs RuntimeLogs
+	err := c.doJSON("GET", path, nil, &logs)
+	return &logs, err
+}
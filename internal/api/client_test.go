@@ -136,7 +136,7 @@ func TestCreateVersion(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	vr, err := client.CreateVersion("my-app", "name: my-app\n", zipPath)
+	vr, err := client.CreateVersion("my-app", "name: my-app\n", zipPath, "zip")
 	if err != nil {
 		t.Fatalf("CreateVersion failed: %v", err)
 	}
@@ -154,6 +154,84 @@ func TestCreateVersion(t *testing.T) {
 	}
 }
 
+func TestCreateVersionTarArchive(t *testing.T) {
+	var gotFormat, gotArchiveName string
+	var gotZip bool
+
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Errorf("ParseMultipartForm failed: %v", err)
+			return
+		}
+		gotFormat = r.FormValue("archive_format")
+		if file, header, _ := r.FormFile("source_archive"); file != nil {
+			gotArchiveName = header.Filename
+			_ = file.Close()
+		}
+		if file, _, _ := r.FormFile("source_zip"); file != nil {
+			gotZip = true
+			_ = file.Close()
+		}
+
+		w.WriteHeader(201)
+		_ = json.NewEncoder(w).Encode(VersionResponse{ID: 42, Version: "1.0.0", Status: "pending"})
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "source.tar.zst")
+	if err := os.WriteFile(archivePath, []byte("fake-tar-content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CreateVersion("my-app", "name: my-app\n", archivePath, "tar.zst"); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+	if gotFormat != "tar.zst" {
+		t.Errorf("expected archive_format tar.zst, got %q", gotFormat)
+	}
+	if gotArchiveName != "source.tar.zst" {
+		t.Errorf("unexpected archive name: %q", gotArchiveName)
+	}
+	if gotZip {
+		t.Error("source_zip should not be sent for tar archives")
+	}
+}
+
+func TestGetCapabilities(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/capabilities" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(Capabilities{ArchiveFormats: []string{"tar.zst", "zip"}})
+	}))
+	defer srv.Close()
+
+	caps, err := client.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	if len(caps.ArchiveFormats) != 2 || caps.ArchiveFormats[0] != "tar.zst" {
+		t.Errorf("unexpected archive formats: %v", caps.ArchiveFormats)
+	}
+}
+
+func TestGetCapabilitiesTestDeployLimits(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"test_deploy":{"default_ttl_seconds":3600,"min_ttl_seconds":600,"max_ttl_seconds":28800}}`))
+	}))
+	defer srv.Close()
+
+	caps, err := client.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	want := TestDeployLimits{DefaultTTLSeconds: 3600, MinTTLSeconds: 600, MaxTTLSeconds: 28800}
+	if caps.TestDeploy != want {
+		t.Errorf("TestDeploy = %+v, want %+v", caps.TestDeploy, want)
+	}
+}
+
 func TestCreateApp(t *testing.T) {
 	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" || r.URL.Path != "/api/v1/apps" {
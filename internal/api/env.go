@@ -0,0 +1,40 @@
+package api
+
+import "net/url"
+
+// EnvVar is a production env var set on an app. Values are write-only: the
+// API only ever returns them masked.
+type EnvVar struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+func envVarsPath(slug string) string {
+	return "/api/v1/apps/" + slug + "/env_vars"
+}
+
+func (c *Client) ListEnvVars(slug string) ([]EnvVar, error) {
+	var resp struct {
+		EnvVars []EnvVar `json:"env_vars"`
+	}
+	err := c.doJSON("GET", envVarsPath(slug), nil, &resp)
+	return resp.EnvVars, err
+}
+
+// SetEnvVars creates or replaces the given vars, leaving others untouched,
+// and returns the updated entries (masked).
+func (c *Client) SetEnvVars(slug string, vars map[string]string) ([]EnvVar, error) {
+	var resp struct {
+		EnvVars []EnvVar `json:"env_vars"`
+	}
+	body := map[string]interface{}{"env_vars": vars}
+	err := c.doJSON("PATCH", envVarsPath(slug), body, &resp)
+	return resp.EnvVars, err
+}
+
+func (c *Client) UnsetEnvVar(slug, key string) (*MessageResponse, error) {
+	var resp MessageResponse
+	err := c.doJSON("DELETE", envVarsPath(slug)+"/"+url.PathEscape(key), nil, &resp)
+	return &resp, err
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestListEnvVars(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v1/apps/my-app/env_vars" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"env_vars": []EnvVar{{Key: "STRIPE_KEY", Value: "sk_l••••••3f9a", UpdatedAt: "2026-03-06T16:00:00Z"}},
+		})
+	}))
+	defer srv.Close()
+
+	vars, err := client.ListEnvVars("my-app")
+	if err != nil {
+		t.Fatalf("ListEnvVars failed: %v", err)
+	}
+	if len(vars) != 1 || vars[0].Key != "STRIPE_KEY" || vars[0].Value != "sk_l••••••3f9a" {
+		t.Errorf("unexpected vars: %+v", vars)
+	}
+}
+
+func TestSetEnvVars(t *testing.T) {
+	var body struct {
+		EnvVars map[string]string `json:"env_vars"`
+	}
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/v1/apps/my-app/env_vars" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"env_vars": []EnvVar{{Key: "SMTP_URL", Value: "••••••••"}},
+		})
+	}))
+	defer srv.Close()
+
+	vars, err := client.SetEnvVars("my-app", map[string]string{"SMTP_URL": "smtp://x"})
+	if err != nil {
+		t.Fatalf("SetEnvVars failed: %v", err)
+	}
+	if body.EnvVars["SMTP_URL"] != "smtp://x" {
+		t.Errorf("unexpected request body: %+v", body)
+	}
+	if len(vars) != 1 || vars[0].Value != "••••••••" {
+		t.Errorf("unexpected vars: %+v", vars)
+	}
+}
+
+func TestUnsetEnvVar(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/api/v1/apps/my-app/env_vars/SMTP_URL" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(MessageResponse{Message: "SMTP_URL removed"})
+	}))
+	defer srv.Close()
+
+	resp, err := client.UnsetEnvVar("my-app", "SMTP_URL")
+	if err != nil {
+		t.Fatalf("UnsetEnvVar failed: %v", err)
+	}
+	if resp.Message != "SMTP_URL removed" {
+		t.Errorf("unexpected message: %q", resp.Message)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/bitfootco/kyper-cli/internal/version"
+	"github.com/gorilla/websocket"
+)
+
+// ExecRequest starts a one-off command in a test deploy. Process selects
+// whose image and environment the command runs with; the server defaults to
+// web.
+type ExecRequest struct {
+	Command []string `json:"command"`
+	Process string   `json:"process,omitempty"`
+	TTY     bool     `json:"tty,omitempty"`
+	Cols    int      `json:"cols,omitempty"`
+	Rows    int      `json:"rows,omitempty"`
+}
+
+// ExecSession is a one-off command running in a test deploy.
+type ExecSession struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+// ExecOutput is a page of output from a non-interactive exec session.
+// ExitCode is only meaningful once Complete is true.
+type ExecOutput struct {
+	Output   string `json:"output"`
+	Cursor   int    `json:"cursor"`
+	Complete bool   `json:"complete"`
+	ExitCode int    `json:"exit_code"`
+}
+
+func (c *Client) CreateTestExec(slug, name string, req ExecRequest) (*ExecSession, error) {
+	var sess ExecSession
+	err := c.doJSON("POST", testDeployPath(slug, name)+"/exec", req, &sess)
+	return &sess, err
+}
+
+func (c *Client) GetTestExecOutput(slug, name string, id, cursor int) (*ExecOutput, error) {
+	var out ExecOutput
+	path := fmt.Sprintf("%s/exec/%d?cursor=%d", testDeployPath(slug, name), id, cursor)
+	err := c.doJSON("GET", path, nil, &out)
+	return &out, err
+}
+
+// AttachTestExec opens the websocket for an interactive (TTY) exec session.
+func (c *Client) AttachTestExec(slug, name string, id int) (*ExecConn, error) {
+	u, err := url.Parse(fmt.Sprintf("%s%s/exec/%d/attach", c.BaseURL, testDeployPath(slug, name), id))
+	if err != nil {
+		return nil, fmt.Errorf("parsing exec URL: %w", err)
+	}
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+
+	// The dialer bypasses HTTPClient, so set the transport's headers here.
+	header := http.Header{}
+	if t, ok := c.HTTPClient.Transport.(*Transport); ok && t.Token != "" {
+		header.Set("Authorization", "Bearer "+t.Token)
+	}
+	header.Set("User-Agent", "kyper-cli/"+version.Version)
+
+	ws, resp, err := websocket.DefaultDialer.Dial(u.String(), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 400 {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, parseAPIError(resp.StatusCode, body)
+		}
+		return nil, fmt.Errorf("connecting to exec session: %w", err)
+	}
+	return &ExecConn{ws: ws}, nil
+}
+
+// ExecConn is an attached interactive exec session. Binary messages carry raw
+// terminal bytes in both directions; text messages carry JSON control
+// messages ("resize" from the client, "exit" from the server).
+type ExecConn struct {
+	ws *websocket.Conn
+	mu sync.Mutex // gorilla/websocket allows one concurrent writer
+}
+
+// ExecFrame is one message received from an exec session: either output or
+// the command's exit.
+type ExecFrame struct {
+	Output   []byte
+	Exited   bool
+	ExitCode int
+}
+
+type execControl struct {
+	Type     string `json:"type"`
+	Cols     int    `json:"cols,omitempty"`
+	Rows     int    `json:"rows,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+}
+
+// Write sends input to the command's terminal.
+func (e *ExecConn) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Resize tells the server the local terminal size changed.
+func (e *ExecConn) Resize(cols, rows int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ws.WriteJSON(execControl{Type: "resize", Cols: cols, Rows: rows})
+}
+
+// Next blocks until the next output or exit frame. Unknown control messages
+// are skipped.
+func (e *ExecConn) Next() (ExecFrame, error) {
+	for {
+		typ, data, err := e.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return ExecFrame{}, fmt.Errorf("exec session closed before the command exited")
+			}
+			return ExecFrame{}, err
+		}
+		switch typ {
+		case websocket.BinaryMessage:
+			return ExecFrame{Output: data}, nil
+		case websocket.TextMessage:
+			var msg execControl
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return ExecFrame{}, fmt.Errorf("parsing exec control message: %w", err)
+			}
+			if msg.Type == "exit" {
+				return ExecFrame{Exited: true, ExitCode: msg.ExitCode}, nil
+			}
+		}
+	}
+}
+
+// Close closes the websocket.
+func (e *ExecConn) Close() error {
+	return e.ws.Close()
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCreateTestExec(t *testing.T) {
+	var got ExecRequest
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/apps/my-app/test_deploy/exec" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(201)
+		_ = json.NewEncoder(w).Encode(ExecSession{ID: 7, Status: "starting"})
+	}))
+	defer srv.Close()
+
+	sess, err := client.CreateTestExec("my-app", "", ExecRequest{Command: []string{"rails", "db:seed"}, Process: "web"})
+	if err != nil {
+		t.Fatalf("CreateTestExec failed: %v", err)
+	}
+	if sess.ID != 7 {
+		t.Errorf("expected session 7, got %d", sess.ID)
+	}
+	if len(got.Command) != 2 || got.Command[1] != "db:seed" || got.Process != "web" || got.TTY {
+		t.Errorf("unexpected request body: %+v", got)
+	}
+}
+
+func TestGetTestExecOutput(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/test_deploy/exec/7" || r.URL.Query().Get("cursor") != "3" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(ExecOutput{Output: "done\n", Cursor: 8, Complete: true, ExitCode: 2})
+	}))
+	defer srv.Close()
+
+	out, err := client.GetTestExecOutput("my-app", "", 7, 3)
+	if err != nil {
+		t.Fatalf("GetTestExecOutput failed: %v", err)
+	}
+	if !out.Complete || out.ExitCode != 2 || out.Cursor != 8 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
+
+func TestAttachTestExec(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	received := make(chan string, 2)
+
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/test_deploy/exec/7/attach" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer func() { _ = ws.Close() }()
+
+		for i := 0; i < 2; i++ {
+			_, data, err := ws.ReadMessage()
+			if err != nil {
+				t.Errorf("reading client message: %v", err)
+				return
+			}
+			received <- string(data)
+		}
+		_ = ws.WriteMessage(websocket.BinaryMessage, []byte("irb> "))
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"heartbeat"}`))
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"exit","exit_code":3}`))
+	}))
+	defer srv.Close()
+
+	conn, err := client.AttachTestExec("my-app", "", 7)
+	if err != nil {
+		t.Fatalf("AttachTestExec failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("exit\r")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := conn.Resize(120, 40); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	if got := <-received; got != "exit\r" {
+		t.Errorf("server got input %q", got)
+	}
+	if got := <-received; got != `{"type":"resize","cols":120,"rows":40}`+"\n" {
+		t.Errorf("server got control %q", got)
+	}
+
+	frame, err := conn.Next()
+	if err != nil || string(frame.Output) != "irb> " {
+		t.Fatalf("expected output frame, got %+v (%v)", frame, err)
+	}
+	frame, err = conn.Next()
+	if err != nil || !frame.Exited || frame.ExitCode != 3 {
+		t.Fatalf("expected exit frame with code 3, got %+v (%v)", frame, err)
+	}
+}
+
+func TestAttachTestExecNotFound(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "no such session"})
+	}))
+	defer srv.Close()
+
+	_, err := client.AttachTestExec("my-app", "", 7)
+	if !IsNotFound(err) {
+		t.Errorf("expected IsNotFound, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateTestDeploy(t *testing.T) {
@@ -50,7 +51,7 @@ func TestCreateTestDeploy(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	resp, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, nil)
+	resp, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, "zip", TestDeployOptions{})
 	if err != nil {
 		t.Fatalf("CreateTestDeploy failed: %v", err)
 	}
@@ -82,7 +83,7 @@ func TestCreateTestDeployError(t *testing.T) {
 	zipPath := filepath.Join(dir, "source.zip")
 	_ = os.WriteFile(zipPath, []byte("fake"), 0644)
 
-	_, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, nil)
+	_, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, "zip", TestDeployOptions{})
 	if err == nil {
 		t.Fatal("expected error on 429")
 	}
@@ -114,7 +115,7 @@ func TestCreateTestDeployWithEnvVars(t *testing.T) {
 	_ = os.WriteFile(zipPath, []byte("fake"), 0644)
 
 	envVars := map[string]string{"MY_KEY": "hello", "OTHER": "world"}
-	_, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, envVars)
+	_, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, "zip", TestDeployOptions{EnvVars: envVars})
 	if err != nil {
 		t.Fatalf("CreateTestDeploy failed: %v", err)
 	}
@@ -130,6 +131,31 @@ func TestCreateTestDeployWithEnvVars(t *testing.T) {
 	}
 }
 
+func TestCreateTestDeployWithTTL(t *testing.T) {
+	var gotTTL string
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Errorf("ParseMultipartForm failed: %v", err)
+			return
+		}
+		gotTTL = r.FormValue("ttl_seconds")
+		w.WriteHeader(201)
+		_ = json.NewEncoder(w).Encode(TestDeployResponse{VersionID: 1, Message: "queued."})
+	}))
+	defer srv.Close()
+
+	zipPath := filepath.Join(t.TempDir(), "source.zip")
+	_ = os.WriteFile(zipPath, []byte("fake"), 0644)
+
+	_, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, "zip", TestDeployOptions{TTL: 3 * time.Hour})
+	if err != nil {
+		t.Fatalf("CreateTestDeploy failed: %v", err)
+	}
+	if gotTTL != "10800" {
+		t.Errorf("expected ttl_seconds=10800, got %q", gotTTL)
+	}
+}
+
 func TestGetTestDeploy(t *testing.T) {
 	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/apps/my-app/test_deploy" {
@@ -153,7 +179,7 @@ func TestGetTestDeploy(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	status, err := client.GetTestDeploy("my-app", 42)
+	status, err := client.GetTestDeploy("my-app", "", 42)
 	if err != nil {
 		t.Fatalf("GetTestDeploy failed: %v", err)
 	}
@@ -184,7 +210,7 @@ func TestGetTestDeployNullDeployment(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	status, err := client.GetTestDeploy("my-app", 0)
+	status, err := client.GetTestDeploy("my-app", "", 0)
 	if err != nil {
 		t.Fatalf("GetTestDeploy failed: %v", err)
 	}
@@ -200,7 +226,7 @@ func TestGetTestDeploy404(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := client.GetTestDeploy("my-app", 0)
+	_, err := client.GetTestDeploy("my-app", "", 0)
 	if !IsNotFound(err) {
 		t.Errorf("expected IsNotFound to be true, got %v", err)
 	}
@@ -218,7 +244,7 @@ func TestDeleteTestDeploy(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	resp, err := client.DeleteTestDeploy("my-app")
+	resp, err := client.DeleteTestDeploy("my-app", "")
 	if err != nil {
 		t.Fatalf("DeleteTestDeploy failed: %v", err)
 	}
@@ -234,9 +260,141 @@ func TestDeleteTestDeploy404(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := client.DeleteTestDeploy("my-app")
+	_, err := client.DeleteTestDeploy("my-app", "")
 	if !IsNotFound(err) {
 		t.Errorf("expected IsNotFound to be true, got %v", err)
 	}
 }
 
+
+func TestGetTestDeployLogs(t *testing.T) {
+	since := time.Date(2026, 3, 6, 16, 0, 0, 0, time.UTC)
+
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/test_deploy/logs" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("process") != "web" {
+			t.Errorf("expected process=web, got %q", q.Get("process"))
+		}
+		if q.Get("since") != "2026-03-06T16:00:00Z" {
+			t.Errorf("unexpected since: %q", q.Get("since"))
+		}
+		if q.Get("cursor") != "12" {
+			t.Errorf("expected cursor=12, got %q", q.Get("cursor"))
+		}
+		_ = json.NewEncoder(w).Encode(RuntimeLogs{
+			Lines: []RuntimeLogLine{
+				{Timestamp: "2026-03-06T16:00:01Z", Process: "web", Stream: "stderr", Message: "boom"},
+			},
+			Cursor: 13,
+		})
+	}))
+	defer srv.Close()
+
+	logs, err := client.GetTestDeployLogs("my-app", "", RuntimeLogOptions{Process: "web", Since: since, Cursor: 12})
+	if err != nil {
+		t.Fatalf("GetTestDeployLogs failed: %v", err)
+	}
+	if logs.Cursor != 13 {
+		t.Errorf("expected cursor 13, got %d", logs.Cursor)
+	}
+	if len(logs.Lines) != 1 || logs.Lines[0].Message != "boom" || logs.Lines[0].Stream != "stderr" {
+		t.Errorf("unexpected lines: %+v", logs.Lines)
+	}
+}
+
+func TestGetTestDeployLogsNoFilters(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("expected no query string, got %q", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(RuntimeLogs{})
+	}))
+	defer srv.Close()
+
+	if _, err := client.GetTestDeployLogs("my-app", "", RuntimeLogOptions{}); err != nil {
+		t.Fatalf("GetTestDeployLogs failed: %v", err)
+	}
+}
+
+func TestExtendTestDeploy(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/apps/my-app/test_deploy/extend" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]int
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["extend_by_seconds"] != 1800 {
+			t.Errorf("expected extend_by_seconds=1800, got %v", body)
+		}
+		_ = json.NewEncoder(w).Encode(TestDeployment{Status: "running", ExpiresAt: "2026-03-06T17:30:00Z"})
+	}))
+	defer srv.Close()
+
+	d, err := client.ExtendTestDeploy("my-app", "", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("ExtendTestDeploy failed: %v", err)
+	}
+	if d.ExpiresAt != "2026-03-06T17:30:00Z" {
+		t.Errorf("unexpected expires_at: %q", d.ExpiresAt)
+	}
+}
+
+func TestNamedTestDeployPaths(t *testing.T) {
+	var paths []string
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			w.WriteHeader(201)
+			_ = json.NewEncoder(w).Encode(TestDeployResponse{VersionID: 1})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "ok"})
+	}))
+	defer srv.Close()
+
+	zipPath := filepath.Join(t.TempDir(), "source.zip")
+	_ = os.WriteFile(zipPath, []byte("fake"), 0644)
+
+	if _, err := client.CreateTestDeploy("my-app", "name: my-app\n", zipPath, "zip", TestDeployOptions{Name: "pr-123"}); err != nil {
+		t.Fatalf("CreateTestDeploy failed: %v", err)
+	}
+	if _, err := client.GetTestDeploy("my-app", "pr-123", 0); err != nil {
+		t.Fatalf("GetTestDeploy failed: %v", err)
+	}
+	if _, err := client.DeleteTestDeploy("my-app", "pr-123"); err != nil {
+		t.Fatalf("DeleteTestDeploy failed: %v", err)
+	}
+
+	want := []string{
+		"POST /api/v1/apps/my-app/test_deploys/pr-123",
+		"GET /api/v1/apps/my-app/test_deploys/pr-123",
+		"DELETE /api/v1/apps/my-app/test_deploys/pr-123",
+	}
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(paths, "\n"))
+	}
+}
+
+func TestListTestDeploys(t *testing.T) {
+	client, srv := testClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/test_deploys" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"test_deploys":[
+			{"name":"","status":"running","url":"https://test-a.example","expires_at":"2026-03-06T17:00:00Z"},
+			{"name":"pr-123","status":"provisioning","url":"","expires_at":""}
+		]}`))
+	}))
+	defer srv.Close()
+
+	deploys, err := client.ListTestDeploys("my-app")
+	if err != nil {
+		t.Fatalf("ListTestDeploys failed: %v", err)
+	}
+	if len(deploys) != 2 || deploys[0].URL != "https://test-a.example" || deploys[1].Name != "pr-123" {
+		t.Errorf("unexpected deploys: %+v", deploys)
+	}
+}
@@ -1,8 +1,7 @@
 package archive
 
 import (
-	"archive/zip"
-	"io"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,31 +15,66 @@ var defaultExcludes = []string{
 	"node_modules/",
 }
 
+// Result describes what Create skipped while building the archive.
+type Result struct {
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // Create builds a zip archive from the given directory, respecting
 // default exclude patterns, .dockerignore, and .kyperignore rules.
-func Create(dir, outputPath string) error {
-	dockerPatterns := loadDockerignorePatterns(dir)
-	ignorePatterns := loadIgnorePatterns(dir)
-	allPatterns := make([]string, 0, len(defaultExcludes)+len(dockerPatterns)+len(ignorePatterns))
-	allPatterns = append(allPatterns, defaultExcludes...)
-	allPatterns = append(allPatterns, dockerPatterns...)
-	allPatterns = append(allPatterns, ignorePatterns...)
+func Create(dir, outputPath string) (*Result, error) {
+	return CreateWithOptions(dir, outputPath, Options{Format: FormatZip})
+}
+
+// Options configures CreateWithOptions.
+type Options struct {
+	// Format is the archive encoding; zero value means zip.
+	Format Format
+	// IgnoreDirs are subdirectories of the archived dir whose .kyperignore
+	// rules also apply, scoped to that subtree. A monorepo app uses this so
+	// its own ignore rules hold when the build context is the repo root.
+	IgnoreDirs []string
+}
+
+// CreateWithOptions builds an archive of dir, applying the same exclude
+// rules as Create plus any configured in opts.
+//
+// Symlinks are stored as links rather than followed. A symlink whose target
+// resolves outside dir is an error, since the server could never resolve it
+// and following it would leak files from outside the project. Sockets, FIFOs
+// and devices are skipped with a warning. File modes are normalized to 0644,
+// or 0755 when any execute bit is set, so scripts stay executable regardless
+// of the local umask.
+func CreateWithOptions(dir, outputPath string, opts Options) (*Result, error) {
+	matcher := NewMatcher(dir, opts)
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	absOut, err := filepath.Abs(outputPath)
+	if err != nil {
+		return nil, err
+	}
 
 	outFile, err := os.Create(outputPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() { _ = outFile.Close() }()
 
-	w := zip.NewWriter(outFile)
-	defer func() { _ = w.Close() }()
+	w, err := NewWriter(outFile, opts.Format)
+	if err != nil {
+		return nil, err
+	}
 
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	result := &Result{}
+	err = filepath.Walk(absDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		relPath, err := filepath.Rel(dir, path)
+		relPath, err := filepath.Rel(absDir, path)
 		if err != nil {
 			return err
 		}
@@ -51,36 +85,36 @@ func Create(dir, outputPath string) error {
 		}
 
 		// Check exclusion patterns
-		if shouldExclude(relPath, info.IsDir(), allPatterns) {
+		if matcher.Excluded(relPath, info.IsDir()) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Skip directories themselves (they're implicit in zip)
+		// Skip directories themselves (they're implicit in the archive)
 		if info.IsDir() {
 			return nil
 		}
 
 		// Don't include the output file itself
-		absOut, _ := filepath.Abs(outputPath)
-		absPath, _ := filepath.Abs(path)
-		if absOut == absPath {
+		if path == absOut {
 			return nil
 		}
 
-		// Add file to zip
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-		header.Name = filepath.ToSlash(relPath)
-		header.Method = zip.Deflate
-
-		writer, err := w.CreateHeader(header)
-		if err != nil {
-			return err
+		name := filepath.ToSlash(relPath)
+		mode := info.Mode()
+		switch {
+		case mode&os.ModeSymlink != 0:
+			target, err := resolveSymlink(absDir, path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			return w.AddSymlink(name, filepath.ToSlash(target))
+		case !mode.IsRegular():
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("skipping %s: %s is not a regular file", name, describeMode(mode)))
+			return nil
 		}
 
 		file, err := os.Open(path)
@@ -89,9 +123,110 @@ func Create(dir, outputPath string) error {
 		}
 		defer func() { _ = file.Close() }()
 
-		_, err = io.Copy(writer, file)
-		return err
+		return w.AddFile(name, normalizeMode(mode), info.ModTime(), info.Size(), file)
 	})
+	if err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := outFile.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveSymlink returns the link target to store for the symlink at path,
+// or an error if the target resolves outside absDir.
+func resolveSymlink(absDir, path string) (string, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(path), resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	if !withinDir(absDir, resolved) {
+		// The project dir itself may sit behind a symlink (e.g. /tmp on macOS),
+		// so also accept absolute targets that use the fully resolved form.
+		realDir, evalErr := filepath.EvalSymlinks(absDir)
+		if evalErr != nil || !filepath.IsAbs(target) || !withinDir(realDir, resolved) {
+			return "", fmt.Errorf("symlink target %q is outside the project directory", target)
+		}
+		rel, relErr := filepath.Rel(realDir, resolved)
+		if relErr != nil {
+			return "", relErr
+		}
+		resolved = filepath.Join(absDir, rel)
+	}
+
+	if !filepath.IsAbs(target) {
+		return target, nil
+	}
+	return filepath.Rel(filepath.Dir(path), resolved)
+}
+
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// normalizeMode maps a local file mode to 0755 or 0644 so archives don't
+// depend on the uploader's umask.
+func normalizeMode(mode os.FileMode) os.FileMode {
+	if mode.Perm()&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+func describeMode(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeDevice != 0:
+		return "device"
+	default:
+		return "special file"
+	}
+}
+
+// Matcher reports which paths an archive of a directory would exclude.
+// Watchers use it to ignore changes that wouldn't affect the upload.
+type Matcher struct {
+	patterns []string
+}
+
+// NewMatcher loads the default excludes plus dir's .dockerignore and
+// .kyperignore, and the scoped .kyperignore of each of opts.IgnoreDirs.
+func NewMatcher(dir string, opts Options) *Matcher {
+	dockerPatterns := loadDockerignorePatterns(dir)
+	ignorePatterns := loadIgnorePatterns(dir)
+	patterns := make([]string, 0, len(defaultExcludes)+len(dockerPatterns)+len(ignorePatterns))
+	patterns = append(patterns, defaultExcludes...)
+	patterns = append(patterns, dockerPatterns...)
+	patterns = append(patterns, ignorePatterns...)
+	for _, sub := range opts.IgnoreDirs {
+		patterns = append(patterns, scopedIgnorePatterns(dir, sub)...)
+	}
+	return &Matcher{patterns: patterns}
+}
+
+// Excluded reports whether relPath, relative to the matcher's dir, is
+// excluded from the archive.
+func (m *Matcher) Excluded(relPath string, isDir bool) bool {
+	return shouldExclude(relPath, isDir, m.patterns)
 }
 
 func loadDockerignorePatterns(dir string) []string {
@@ -129,6 +264,25 @@ func loadIgnorePatterns(dir string) []string {
 	return patterns
 }
 
+// scopedIgnorePatterns loads sub's .kyperignore and rewrites path patterns
+// so they match relative to dir. Bare names and directory patterns already
+// match at any depth and are kept as-is.
+func scopedIgnorePatterns(dir, sub string) []string {
+	rel, err := filepath.Rel(dir, sub)
+	if err != nil || rel == "." {
+		return loadIgnorePatterns(sub)
+	}
+
+	var patterns []string
+	for _, p := range loadIgnorePatterns(sub) {
+		if strings.Contains(strings.TrimSuffix(p, "/"), "/") {
+			p = filepath.Join(rel, strings.TrimPrefix(p, "/"))
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
 func shouldExclude(relPath string, isDir bool, patterns []string) bool {
 	name := filepath.Base(relPath)
 
@@ -2,8 +2,11 @@ package archive
 
 import (
 	"archive/zip"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 )
 
@@ -25,7 +28,7 @@ func TestCreateZip(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -66,7 +69,7 @@ func TestCreateZipExcludesGit(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -97,7 +100,7 @@ func TestCreateZipExcludesNodeModules(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -128,7 +131,7 @@ func TestCreateZipRespectsKyperignore(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -159,7 +162,7 @@ func TestCreateZipRespectsDockerignore(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -202,7 +205,7 @@ func TestCreateZipDockerignoreSkipsNegations(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -234,7 +237,7 @@ func TestCreateZipExcludesLogFiles(t *testing.T) {
 	}
 
 	outPath := filepath.Join(t.TempDir(), "output.zip")
-	if err := Create(dir, outPath); err != nil {
+	if _, err := Create(dir, outPath); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 
@@ -250,3 +253,210 @@ func TestCreateZipExcludesLogFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateZipStoresSymlinkInsideProject(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "app.yml"), []byte("key: value"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("config/app.yml", filepath.Join(dir, "app.yml")); err != nil {
+		t.Fatal(err)
+	}
+	// Absolute targets inside the project are rewritten relative to the link.
+	if err := os.Symlink(filepath.Join(dir, "config"), filepath.Join(dir, "conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	result, err := Create(dir, outPath)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+
+	r, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("opening zip: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	targets := map[string]string{}
+	for _, f := range r.File {
+		if f.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		targets[f.Name] = string(data)
+	}
+
+	if targets["app.yml"] != "config/app.yml" {
+		t.Errorf("expected app.yml -> config/app.yml, got %q", targets["app.yml"])
+	}
+	if targets["conf"] != "config" {
+		t.Errorf("expected conf -> config, got %q", targets["conf"])
+	}
+}
+
+func TestCreateZipRejectsSymlinkOutsideProject(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("shh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "secret.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	_, err := Create(dir, outPath)
+	if err == nil {
+		t.Fatal("expected error for symlink pointing outside the project")
+	}
+	if !strings.Contains(err.Error(), "outside the project") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateZipRejectsRelativeSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.Symlink("../../etc/passwd", filepath.Join(dir, "passwd")); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	if _, err := Create(dir, outPath); err == nil {
+		t.Fatal("expected error for relative symlink escaping the project")
+	}
+}
+
+func TestCreateZipSkipsSpecialFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "app.rb"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(dir, "pipe"), 0644); err != nil {
+		t.Skipf("mkfifo not supported: %v", err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	result, err := Create(dir, outPath)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "pipe") {
+		t.Errorf("expected one warning about pipe, got %v", result.Warnings)
+	}
+
+	r, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("opening zip: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		if f.Name == "pipe" {
+			t.Error("zip should not contain named pipes")
+		}
+	}
+}
+
+func TestCreateZipNormalizesModes(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin", "docker-entrypoint"), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.rb"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	if _, err := Create(dir, outPath); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	r, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("opening zip: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	modes := map[string]os.FileMode{}
+	for _, f := range r.File {
+		modes[f.Name] = f.Mode()
+	}
+	if modes["bin/docker-entrypoint"] != 0755 {
+		t.Errorf("expected bin/docker-entrypoint mode 0755, got %v", modes["bin/docker-entrypoint"])
+	}
+	if modes["app.rb"] != 0644 {
+		t.Errorf("expected app.rb mode 0644, got %v", modes["app.rb"])
+	}
+}
+
+func TestCreateAppliesScopedIgnoreDirs(t *testing.T) {
+	dir := t.TempDir()
+	appDir := filepath.Join(dir, "apps", "web")
+
+	if err := os.MkdirAll(filepath.Join(appDir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "packages", "ui", "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"apps/web/.kyperignore":         ".env\nconfig/master.key\n",
+		"apps/web/.env":                 "SECRET=1",
+		"apps/web/config/master.key":    "key",
+		"apps/web/app.rb":               "hello",
+		"packages/ui/config/master.key": "shared",
+		"packages/ui/index.js":          "export {}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outPath := filepath.Join(t.TempDir(), "output.zip")
+	if _, err := CreateWithOptions(dir, outPath, Options{IgnoreDirs: []string{appDir}}); err != nil {
+		t.Fatalf("CreateWithOptions failed: %v", err)
+	}
+
+	r, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("opening zip: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	got := map[string]bool{}
+	for _, f := range r.File {
+		got[f.Name] = true
+	}
+	for _, name := range []string{"apps/web/app.rb", "packages/ui/index.js", "packages/ui/config/master.key"} {
+		if !got[name] {
+			t.Errorf("expected %s in zip", name)
+		}
+	}
+	for _, name := range []string{"apps/web/.env", "apps/web/config/master.key"} {
+		if got[name] {
+			t.Errorf("zip should not contain %s (in apps/web/.kyperignore)", name)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format identifies an archive container and compression scheme.
+type Format string
+
+const (
+	FormatZip    Format = "zip"
+	FormatTarGz  Format = "tar.gz"
+	FormatTarZst Format = "tar.zst"
+)
+
+// Formats lists every format the CLI can write, in no particular preference.
+var Formats = []Format{FormatZip, FormatTarGz, FormatTarZst}
+
+// ParseFormat validates a user-supplied format name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown archive format %q — supported: %s", s, strings.Join(names, ", "))
+}
+
+// Ext returns the file extension for the format, including the leading dot.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// Negotiate picks the archive format for a push. Zip stays the default
+// whenever the server accepts it; otherwise the first advertised format the
+// CLI can write is used. Servers that advertise nothing only accept zip.
+func Negotiate(advertised []string) Format {
+	if len(advertised) == 0 || slices.Contains(advertised, string(FormatZip)) {
+		return FormatZip
+	}
+	for _, a := range advertised {
+		if f, err := ParseFormat(a); err == nil {
+			return f
+		}
+	}
+	return FormatZip
+}
+
+// Supports reports whether the server accepts f. Zip is always accepted.
+func Supports(advertised []string, f Format) bool {
+	if f == FormatZip {
+		return true
+	}
+	for _, a := range advertised {
+		if a == string(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer adds entries to an archive. Implementations exist for each Format.
+type Writer interface {
+	AddFile(name string, mode os.FileMode, modTime time.Time, size int64, r io.Reader) error
+	AddSymlink(name, target string) error
+	Close() error
+}
+
+// NewWriter returns a Writer that encodes entries to w in the given format.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatZip, "":
+		return &zipWriter{w: zip.NewWriter(w)}, nil
+	case FormatTarGz:
+		return newTarWriter(gzip.NewWriter(w)), nil
+	case FormatTarZst:
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd encoder: %w", err)
+		}
+		return newTarWriter(enc), nil
+	}
+	return nil, fmt.Errorf("unknown archive format %q", format)
+}
+
+type zipWriter struct {
+	w *zip.Writer
+}
+
+func (z *zipWriter) AddFile(name string, mode os.FileMode, modTime time.Time, size int64, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:               name,
+		Method:             zip.Deflate,
+		Modified:           modTime,
+		UncompressedSize64: uint64(size),
+	}
+	header.SetMode(mode)
+
+	writer, err := z.w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, r)
+	return err
+}
+
+// AddSymlink stores the link target as the entry content, the same layout
+// Info-ZIP uses.
+func (z *zipWriter) AddSymlink(name, target string) error {
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Store,
+	}
+	header.SetMode(os.ModeSymlink | 0777)
+
+	writer, err := z.w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, target)
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.w.Close()
+}
+
+type tarWriter struct {
+	tw         *tar.Writer
+	compressor io.WriteCloser
+}
+
+func newTarWriter(compressor io.WriteCloser) *tarWriter {
+	return &tarWriter{tw: tar.NewWriter(compressor), compressor: compressor}
+}
+
+func (t *tarWriter) AddFile(name string, mode os.FileMode, modTime time.Time, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+		ModTime:  modTime,
+		Size:     size,
+		Format:   tar.FormatPAX,
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.tw, r)
+	return err
+}
+
+func (t *tarWriter) AddSymlink(name, target string) error {
+	return t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     0777,
+		Format:   tar.FormatPAX,
+	})
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		_ = t.compressor.Close()
+		return err
+	}
+	return t.compressor.Close()
+}
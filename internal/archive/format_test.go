@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		got, err := ParseFormat(string(f))
+		if err != nil {
+			t.Errorf("ParseFormat(%q) failed: %v", f, err)
+		}
+		if got != f {
+			t.Errorf("ParseFormat(%q) = %q", f, got)
+		}
+	}
+	if _, err := ParseFormat("rar"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []string
+		want       Format
+	}{
+		{"nothing advertised", nil, FormatZip},
+		{"zip kept when listed later", []string{"tar.zst", "zip"}, FormatZip},
+		{"zip listed first", []string{"zip", "tar.zst"}, FormatZip},
+		{"no zip", []string{"tar.zst", "tar.gz"}, FormatTarZst},
+		{"unknown formats skipped", []string{"tar.xz", "tar.gz"}, FormatTarGz},
+		{"only unknown formats", []string{"tar.xz"}, FormatZip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.advertised); got != tt.want {
+				t.Errorf("Negotiate(%v) = %q, want %q", tt.advertised, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	if !Supports(nil, FormatZip) {
+		t.Error("zip should always be supported")
+	}
+	if Supports(nil, FormatTarZst) {
+		t.Error("tar.zst should not be supported when nothing is advertised")
+	}
+	if !Supports([]string{"tar.zst"}, FormatTarZst) {
+		t.Error("tar.zst should be supported when advertised")
+	}
+}
+
+func TestCreateTarFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin", "start"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.rb"), []byte("puts 'hello'"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app.rb", filepath.Join(dir, "main.rb")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "debug.log"), []byte("log data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []Format{FormatTarGz, FormatTarZst} {
+		t.Run(string(format), func(t *testing.T) {
+			outPath := filepath.Join(t.TempDir(), "output"+format.Ext())
+			if _, err := CreateWithOptions(dir, outPath, Options{Format: format}); err != nil {
+				t.Fatalf("CreateWithOptions failed: %v", err)
+			}
+
+			entries := readTar(t, outPath, format)
+			if h, ok := entries["app.rb"]; !ok || h.Mode != 0644 {
+				t.Errorf("expected app.rb with mode 0644, got %+v", h)
+			}
+			if h, ok := entries["bin/start"]; !ok || h.Mode != 0755 {
+				t.Errorf("expected bin/start with mode 0755, got %+v", h)
+			}
+			if h, ok := entries["main.rb"]; !ok || h.Typeflag != tar.TypeSymlink || h.Linkname != "app.rb" {
+				t.Errorf("expected main.rb symlink to app.rb, got %+v", h)
+			}
+			if _, ok := entries["debug.log"]; ok {
+				t.Error("archive should not contain .log files")
+			}
+		})
+	}
+}
+
+func readTar(t *testing.T, path string, format Format) map[string]*tar.Header {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening archive: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader
+	switch format {
+	case FormatTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("opening gzip stream: %v", err)
+		}
+		r = gz
+	case FormatTarZst:
+		dec, err := zstd.NewReader(f)
+		if err != nil {
+			t.Fatalf("opening zstd stream: %v", err)
+		}
+		defer dec.Close()
+		r = dec
+	}
+
+	entries := map[string]*tar.Header{}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		entries[h.Name] = h
+	}
+	return entries
+}
@@ -0,0 +1,279 @@
+// Package builder runs local image builds with a container engine CLI:
+// docker (using buildx when it's installed), podman or nerdctl.
+package builder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Engines lists the supported builders in auto-detection order.
+var Engines = []string{"docker", "podman", "nerdctl"}
+
+// Options describes one image build.
+type Options struct {
+	Dockerfile string
+	Context    string
+	Tag        string
+	NoCache    bool
+	Platform   string
+	Target     string
+	Args       map[string]string
+	// Secrets are BuildKit secret specs, e.g. "id=npmrc,src=.npmrc".
+	Secrets []string
+	// CacheFrom and CacheTo are cache specs; see CacheSpec.
+	CacheFrom []string
+	CacheTo   []string
+}
+
+// Builder builds images with one container engine. Implementations
+// translate Options into the engine's own flags.
+type Builder interface {
+	// Name is the engine's CLI name, which also runs compose and images.
+	Name() string
+	// BuildArgs returns the CLI arguments that build o.
+	BuildArgs(o Options) ([]string, error)
+	// Build runs the build, streaming the engine's output.
+	Build(o Options, stdout, stderr io.Writer) error
+	// ImageExists reports whether tag is in the engine's local image store.
+	ImageExists(tag string) bool
+}
+
+// Detect returns the builder named by preferred, or the first engine from
+// Engines found in $PATH when preferred is empty.
+func Detect(preferred string) (Builder, error) {
+	if preferred != "" {
+		b, err := New(preferred)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := exec.LookPath(preferred); err != nil {
+			return nil, fmt.Errorf("builder %q not found in $PATH", preferred)
+		}
+		return b, nil
+	}
+	for _, name := range Engines {
+		if _, err := exec.LookPath(name); err == nil {
+			return New(name)
+		}
+	}
+	return nil, fmt.Errorf("no container builder found in $PATH — install Docker (https://docs.docker.com/get-docker/), Podman or nerdctl")
+}
+
+// New returns the builder for an engine name without checking that it's
+// installed.
+func New(name string) (Builder, error) {
+	switch name {
+	case "docker":
+		return &dockerBuilder{buildx: hasBuildx}, nil
+	case "podman":
+		return podmanBuilder{}, nil
+	case "nerdctl":
+		return nerdctlBuilder{}, nil
+	}
+	return nil, fmt.Errorf("unknown builder %q — use one of: %s", name, strings.Join(Engines, ", "))
+}
+
+// hasBuildx reports whether the docker buildx plugin is installed.
+func hasBuildx() bool {
+	return exec.Command("docker", "buildx", "version").Run() == nil
+}
+
+type dockerBuilder struct {
+	buildx func() bool
+}
+
+func (*dockerBuilder) Name() string { return "docker" }
+
+// BuildArgs uses buildx when available, loading the result into the local
+// image store so later runs can use it. Cache import and export need buildx.
+func (b *dockerBuilder) BuildArgs(o Options) ([]string, error) {
+	if !b.buildx() {
+		if len(o.CacheFrom) > 0 || len(o.CacheTo) > 0 {
+			return nil, fmt.Errorf("build cache import/export needs docker buildx — install it: https://docs.docker.com/go/buildx/")
+		}
+		return append(append([]string{"build"}, commonArgs(o)...), o.Context), nil
+	}
+	args := append([]string{"buildx", "build", "--load"}, commonArgs(o)...)
+	args = append(args, cacheArgs(o)...)
+	return append(args, o.Context), nil
+}
+
+func (b *dockerBuilder) Build(o Options, stdout, stderr io.Writer) error {
+	args, err := b.BuildArgs(o)
+	if err != nil {
+		return err
+	}
+	cmd := command("docker", args, stdout, stderr)
+	if args[0] == "build" && len(o.Secrets) > 0 {
+		// The classic builder only understands --secret with BuildKit on.
+		cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
+	}
+	return cmd.Run()
+}
+
+func (*dockerBuilder) ImageExists(tag string) bool { return imageExists("docker", tag) }
+
+type podmanBuilder struct{}
+
+func (podmanBuilder) Name() string { return "podman" }
+
+// BuildArgs maps cache specs onto podman's --cache-from/--cache-to, which
+// only take a registry repository.
+func (podmanBuilder) BuildArgs(o Options) ([]string, error) {
+	args := append([]string{"build"}, commonArgs(o)...)
+	for _, pair := range []struct {
+		flag  string
+		specs []string
+	}{{"--cache-from", o.CacheFrom}, {"--cache-to", o.CacheTo}} {
+		for _, spec := range pair.specs {
+			ref, err := registryRef(spec)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, pair.flag, ref)
+		}
+	}
+	return append(args, o.Context), nil
+}
+
+func (b podmanBuilder) Build(o Options, stdout, stderr io.Writer) error {
+	args, err := b.BuildArgs(o)
+	if err != nil {
+		return err
+	}
+	// podman writes build steps to stdout; send them to stderr, where docker
+	// and nerdctl report progress, so stdout stays clean for --json.
+	return command("podman", args, stderr, stderr).Run()
+}
+
+func (podmanBuilder) ImageExists(tag string) bool { return imageExists("podman", tag) }
+
+type nerdctlBuilder struct{}
+
+func (nerdctlBuilder) Name() string { return "nerdctl" }
+
+// BuildArgs passes everything through to BuildKit, which nerdctl always
+// builds with; images land in containerd's store without --load.
+func (nerdctlBuilder) BuildArgs(o Options) ([]string, error) {
+	args := append([]string{"build"}, commonArgs(o)...)
+	args = append(args, cacheArgs(o)...)
+	return append(args, o.Context), nil
+}
+
+func (b nerdctlBuilder) Build(o Options, stdout, stderr io.Writer) error {
+	args, err := b.BuildArgs(o)
+	if err != nil {
+		return err
+	}
+	return command("nerdctl", args, stdout, stderr).Run()
+}
+
+func (nerdctlBuilder) ImageExists(tag string) bool { return imageExists("nerdctl", tag) }
+
+// commonArgs returns the flags every engine spells the same way.
+func commonArgs(o Options) []string {
+	args := []string{"-f", o.Dockerfile, "-t", o.Tag}
+	if o.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if o.Platform != "" {
+		args = append(args, "--platform", o.Platform)
+	}
+	if o.Target != "" {
+		args = append(args, "--target", o.Target)
+	}
+
+	keys := make([]string, 0, len(o.Args))
+	for k := range o.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+o.Args[k])
+	}
+
+	for _, s := range o.Secrets {
+		args = append(args, "--secret", s)
+	}
+	return args
+}
+
+// cacheArgs returns BuildKit cache flags.
+func cacheArgs(o Options) []string {
+	var args []string
+	for _, c := range o.CacheFrom {
+		args = append(args, "--cache-from", CacheSpec(c, false))
+	}
+	for _, c := range o.CacheTo {
+		args = append(args, "--cache-to", CacheSpec(c, true))
+	}
+	return args
+}
+
+func command(engine string, args []string, stdout, stderr io.Writer) *exec.Cmd {
+	cmd := exec.Command(engine, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Stdin = os.Stdin
+	return cmd
+}
+
+func imageExists(engine, tag string) bool {
+	return exec.Command(engine, "image", "inspect", tag).Run() == nil
+}
+
+// CacheSpec expands a bare image ref into a registry cache spec, exporting
+// every layer (mode=max) when export is set. Full specs such as
+// "type=local,dest=.cache" are returned unchanged.
+func CacheSpec(spec string, export bool) string {
+	if strings.Contains(spec, "=") {
+		return spec
+	}
+	if export {
+		return "type=registry,ref=" + spec + ",mode=max"
+	}
+	return "type=registry,ref=" + spec
+}
+
+// registryRef returns the image ref of a registry cache spec.
+func registryRef(spec string) (string, error) {
+	if !strings.Contains(spec, "=") {
+		return spec, nil
+	}
+	var typ, ref string
+	for _, field := range strings.Split(spec, ",") {
+		k, v, _ := strings.Cut(field, "=")
+		switch k {
+		case "type":
+			typ = v
+		case "ref":
+			ref = v
+		}
+	}
+	if typ != "registry" || ref == "" {
+		return "", fmt.Errorf("podman only supports registry build caches — use an image ref instead of %q", spec)
+	}
+	return ref, nil
+}
+
+// ResolvePaths makes the relative src and dest paths in a secret or cache
+// spec absolute against dir. Bare image refs are left alone.
+func ResolvePaths(spec, dir string) string {
+	if !strings.Contains(spec, "=") {
+		return spec
+	}
+	fields := strings.Split(spec, ",")
+	for i, field := range fields {
+		k, v, ok := strings.Cut(field, "=")
+		if ok && (k == "src" || k == "dest") && v != "" && !filepath.IsAbs(v) {
+			fields[i] = k + "=" + filepath.Join(dir, v)
+		}
+	}
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,128 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func testOptions() Options {
+	return Options{
+		Dockerfile: "Dockerfile",
+		Context:    ".",
+		Tag:        "kyper-local/app:1.0.0",
+		Platform:   "linux/amd64",
+		Target:     "production",
+		Args:       map[string]string{"RUBY_VERSION": "3.3.5", "BUNDLE_WITHOUT": "development"},
+		Secrets:    []string{"id=npmrc,src=.npmrc"},
+		CacheFrom:  []string{"ghcr.io/acme/app:cache"},
+		CacheTo:    []string{"type=local,dest=/tmp/cache"},
+	}
+}
+
+const commonWant = "-f Dockerfile -t kyper-local/app:1.0.0 --platform linux/amd64 --target production " +
+	"--build-arg BUNDLE_WITHOUT=development --build-arg RUBY_VERSION=3.3.5 --secret id=npmrc,src=.npmrc"
+
+func TestDockerBuildArgs(t *testing.T) {
+	o := testOptions()
+	buildx := &dockerBuilder{buildx: func() bool { return true }}
+	args, err := buildx.BuildArgs(o)
+	if err != nil {
+		t.Fatalf("BuildArgs failed: %v", err)
+	}
+	want := "buildx build --load " + commonWant +
+		" --cache-from type=registry,ref=ghcr.io/acme/app:cache --cache-to type=local,dest=/tmp/cache ."
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("args =\n  %s\nwant\n  %s", got, want)
+	}
+
+	classic := &dockerBuilder{buildx: func() bool { return false }}
+	if _, err := classic.BuildArgs(o); err == nil || !strings.Contains(err.Error(), "buildx") {
+		t.Errorf("expected cache options to need buildx, got %v", err)
+	}
+
+	o.CacheFrom, o.CacheTo = nil, nil
+	o.NoCache = true
+	args, err = classic.BuildArgs(o)
+	if err != nil {
+		t.Fatalf("BuildArgs without buildx failed: %v", err)
+	}
+	if args[0] != "build" || args[5] != "--no-cache" {
+		t.Errorf("unexpected classic build args: %v", args)
+	}
+}
+
+func TestPodmanBuildArgs(t *testing.T) {
+	o := testOptions()
+	if _, err := (podmanBuilder{}).BuildArgs(o); err == nil || !strings.Contains(err.Error(), "registry") {
+		t.Errorf("expected local cache to be rejected, got %v", err)
+	}
+
+	o.CacheTo = []string{"type=registry,ref=ghcr.io/acme/app:cache,mode=max"}
+	args, err := (podmanBuilder{}).BuildArgs(o)
+	if err != nil {
+		t.Fatalf("BuildArgs failed: %v", err)
+	}
+	want := "build " + commonWant + " --cache-from ghcr.io/acme/app:cache --cache-to ghcr.io/acme/app:cache ."
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("args =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+func TestNerdctlBuildArgs(t *testing.T) {
+	args, err := (nerdctlBuilder{}).BuildArgs(testOptions())
+	if err != nil {
+		t.Fatalf("BuildArgs failed: %v", err)
+	}
+	want := "build " + commonWant +
+		" --cache-from type=registry,ref=ghcr.io/acme/app:cache --cache-to type=local,dest=/tmp/cache ."
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("args =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Engines {
+		b, err := New(name)
+		if err != nil || b.Name() != name {
+			t.Errorf("New(%q) = %v, %v", name, b, err)
+		}
+	}
+	if _, err := New("buildah"); err == nil || !strings.Contains(err.Error(), "docker, podman, nerdctl") {
+		t.Errorf("expected unknown builder error, got %v", err)
+	}
+	if _, err := Detect("nerdctl-missing"); err == nil {
+		t.Error("expected Detect to reject an unknown builder")
+	}
+}
+
+func TestCacheSpec(t *testing.T) {
+	tests := []struct {
+		spec   string
+		export bool
+		want   string
+	}{
+		{"ghcr.io/acme/app:cache", false, "type=registry,ref=ghcr.io/acme/app:cache"},
+		{"ghcr.io/acme/app:cache", true, "type=registry,ref=ghcr.io/acme/app:cache,mode=max"},
+		{"type=gha", true, "type=gha"},
+	}
+	for _, tt := range tests {
+		if got := CacheSpec(tt.spec, tt.export); got != tt.want {
+			t.Errorf("CacheSpec(%q, %v) = %q, want %q", tt.spec, tt.export, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	tests := []struct{ spec, want string }{
+		{"id=npmrc,src=.npmrc", "id=npmrc,src=/app/.npmrc"},
+		{"type=local,dest=.cache,mode=max", "type=local,dest=/app/.cache,mode=max"},
+		{"type=local,src=/abs/cache", "type=local,src=/abs/cache"},
+		{"ghcr.io/acme/app:cache", "ghcr.io/acme/app:cache"},
+		{"id=token,env=GITHUB_TOKEN", "id=token,env=GITHUB_TOKEN"},
+	}
+	for _, tt := range tests {
+		if got := ResolvePaths(tt.spec, "/app"); got != tt.want {
+			t.Errorf("ResolvePaths(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -4,16 +4,50 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 
+	"github.com/bitfootco/kyper-cli/internal/builder"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/dotenv"
 	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/scan"
 	"github.com/bitfootco/kyper-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
-var buildNoCache bool
+var (
+	buildNoCache       bool
+	buildRunHooks      bool
+	buildVerify        bool
+	buildVerifyTimeout time.Duration
+	buildPlatform      string
+	buildTarget        string
+	buildArgs          []string
+	buildSecrets       []string
+	buildCacheFrom     []string
+	buildCacheTo       []string
+	builderName        string
+	buildScan          bool
+	buildScanner       string
+	buildScanSeverity  string
+)
 
 func init() {
 	buildCmd.Flags().BoolVar(&buildNoCache, "no-cache", false, "Build without Docker layer caching")
+	buildCmd.Flags().BoolVar(&buildRunHooks, "run-hooks", false, "Run the on_deploy and on_update hooks against the new image and fresh deps")
+	buildCmd.Flags().BoolVar(&buildVerify, "verify", false, "Boot the image with its deps and wait for healthcheck.path to respond")
+	buildCmd.Flags().DurationVar(&buildVerifyTimeout, "verify-timeout", 3*time.Minute, "How long --verify waits for the app to become healthy")
+	buildCmd.Flags().StringVar(&buildPlatform, "platform", "", "Target platform, e.g. linux/amd64 (overrides build.platform)")
+	buildCmd.Flags().StringVar(&buildTarget, "target", "", "Dockerfile stage to build (overrides build.target)")
+	buildCmd.Flags().StringArrayVar(&buildArgs, "build-arg", nil, "Build arg KEY=VALUE, or KEY to take it from the environment (repeatable)")
+	buildCmd.Flags().StringArrayVar(&buildSecrets, "secret", nil, "BuildKit secret, e.g. id=npmrc,src=.npmrc (repeatable)")
+	buildCmd.Flags().StringArrayVar(&buildCacheFrom, "cache-from", nil, "Import build cache from an image ref or cache spec (repeatable; needs buildx)")
+	buildCmd.Flags().StringArrayVar(&buildCacheTo, "cache-to", nil, "Export build cache to an image ref or cache spec (repeatable; needs buildx)")
+	buildCmd.Flags().BoolVar(&buildScan, "scan", false, "Scan the new image for vulnerabilities with trivy or grype")
+	buildCmd.Flags().StringVar(&buildScanner, "scanner", "", "Scanner for --scan: trivy or grype (default: whichever is installed)")
+	buildCmd.Flags().StringVar(&buildScanSeverity, "scan-severity", scan.DefaultThreshold, "Fail --scan on findings at or above this severity: LOW, MEDIUM, HIGH or CRITICAL")
+	addBuilderFlag(buildCmd)
 	rootCmd.AddCommand(buildCmd)
 }
 
@@ -22,9 +56,10 @@ var buildCmd = &cobra.Command{
 	Short: "Build the Docker image locally",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// 1. Check docker is available
-		if _, err := exec.LookPath("docker"); err != nil {
-			return fmt.Errorf("docker not found in $PATH — install Docker: https://docs.docker.com/get-docker/")
+		// 1. Find a container builder
+		b, err := resolveBuilder()
+		if err != nil {
+			return err
 		}
 
 		// 2. Load + validate kyper.yml
@@ -32,71 +67,249 @@ var buildCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-
-		result := kyperfile.Validate(kf, true)
-		if !result.Valid {
-			if jsonOutput {
-				_ = ui.PrintJSON(result)
-				return fmt.Errorf("kyper.yml validation failed")
-			}
-			for _, e := range result.Errors {
-				ui.PrintError(e)
+		if err := validateForBuild(kf); err != nil {
+			return err
+		}
+		var scanner, scanSeverity string
+		if buildScan {
+			if scanner, scanSeverity, err = resolveScan(buildScanner, buildScanSeverity); err != nil {
+				return err
 			}
-			return fmt.Errorf("kyper.yml validation failed — run 'kyper validate' for details")
 		}
-		for _, w := range result.Warnings {
-			ui.PrintWarning(w)
+
+		// 3-4. Build image
+		imageTag := localImageTag(kf)
+		if err := buildImage(b, kf, imageTag, buildNoCache); err != nil {
+			return err
 		}
 
 		if !jsonOutput {
-			ui.PrintSuccess("kyper.yml is valid")
+			fmt.Println()
+			ui.PrintSuccess(fmt.Sprintf("Build succeeded: %s", imageTag))
+			fmt.Println()
+		}
+		out := map[string]interface{}{
+			"image":   imageTag,
+			"builder": b.Name(),
+			"status":  "success",
 		}
 
-		// 3. Confirm Dockerfile exists
-		dockerfile := kf.Docker.Dockerfile
-		if _, err := os.Stat(dockerfile); os.IsNotExist(err) {
-			return fmt.Errorf("dockerfile not found: %s", dockerfile)
+		// 5. Optionally scan, run hooks and boot the image
+		var checkErr error
+		if buildScan {
+			s, err := scanImage(b, imageTag, scanner, scanSeverity)
+			if err != nil {
+				return err
+			}
+			out["scan"] = s
+			checkErr = reportScan(s)
+		}
+		if buildRunHooks && checkErr == nil {
+			results, err := buildHooks(b, kf, imageTag)
+			if err != nil {
+				return err
+			}
+			out["hooks"] = results
+			checkErr = reportHooks(results)
+		}
+		if buildVerify && checkErr == nil {
+			v, err := verifyImage(b, kf, imageTag, buildVerifyTimeout)
+			if err != nil {
+				return err
+			}
+			out["verify"] = v
+			checkErr = reportVerify(v)
 		}
 
-		// 4. Build image
-		imageTag := fmt.Sprintf("kyper-local/%s:%s", slugFromTitle(kf.Name), kf.Version)
+		// 6. Report
+		if jsonOutput {
+			if err := ui.PrintJSON(out); err != nil {
+				return err
+			}
+			return checkErr
+		}
+		if checkErr == nil {
+			ui.PrintInfo("Run: kyper push")
+		}
+		return checkErr
+	},
+}
 
+// buildHooks runs every declared hook against a freshly built image.
+func buildHooks(b builder.Builder, kf *config.KyperFile, imageTag string) ([]hookResult, error) {
+	hooks := declaredHooks(kf)
+	if len(hooks) == 0 {
 		if !jsonOutput {
-			fmt.Printf("Building %s from %s\n\n", ui.Bold.Render(imageTag), dockerfile)
+			ui.PrintWarning("kyper.yml declares no hooks — nothing to run")
+		}
+		return []hookResult{}, nil
+	}
+	if err := requireCompose(b); err != nil {
+		return nil, err
+	}
+	env, err := loadEnvFile(kf, kf.ResolvePath(".env"), false)
+	if err != nil {
+		return nil, err
+	}
+	return runHooks(b, kf, imageTag, env, hooks)
+}
+
+// addBuilderFlag registers --builder on a command that builds or runs images.
+func addBuilderFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&builderName, "builder", "", "Container engine to use: docker, podman or nerdctl (default: builder in ~/.kyper/config.yml, else auto-detect)")
+}
+
+// resolveBuilder picks the container engine from --builder, then the
+// builder setting in ~/.kyper/config.yml, then whichever is installed.
+func resolveBuilder() (builder.Builder, error) {
+	name := builderName
+	if name == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
 		}
+		name = cfg.Builder
+	}
+	return builder.Detect(name)
+}
 
-		buildArgs := []string{"build", "-f", dockerfile, "-t", imageTag}
-		if buildNoCache {
-			buildArgs = append(buildArgs, "--no-cache")
+// validateForBuild validates kyper.yml, printing errors and warnings.
+func validateForBuild(kf *config.KyperFile) error {
+	result := kyperfile.Validate(kf, true)
+	if !result.Valid {
+		if jsonOutput {
+			_ = ui.PrintJSON(result)
+			return fmt.Errorf("kyper.yml validation failed")
+		}
+		for _, e := range result.Errors {
+			ui.PrintError(e)
+		}
+		return fmt.Errorf("kyper.yml validation failed — run 'kyper validate' for details")
+	}
+	for _, w := range result.Warnings {
+		ui.PrintWarning(w)
+	}
+
+	if !jsonOutput {
+		ui.PrintSuccess("kyper.yml is valid")
+	}
+	return nil
+}
+
+// localImageTag is the tag kyper build gives the app image.
+func localImageTag(kf *config.KyperFile) string {
+	return fmt.Sprintf("kyper-local/%s:%s", slugFromTitle(kf.Name), kf.Version)
+}
+
+// buildImage builds kf's Dockerfile and context with b, streaming its
+// output — to stderr in JSON mode.
+func buildImage(b builder.Builder, kf *config.KyperFile, imageTag string, noCache bool) error {
+	// Confirm Dockerfile exists
+	dockerfile := kf.DockerfilePath()
+	if _, err := os.Stat(dockerfile); os.IsNotExist(err) {
+		return fmt.Errorf("dockerfile not found: %s", dockerfile)
+	}
+	opts, err := buildOptions(kf, imageTag, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	opts.NoCache = noCache
+
+	if !jsonOutput {
+		fmt.Printf("Building %s from %s\n\n", ui.Bold.Render(imageTag), dockerfile)
+	}
+
+	stdout := os.Stdout
+	if jsonOutput {
+		stdout = os.Stderr
+	}
+	if err := b.Build(opts, stdout, os.Stderr); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return err
 		}
-		buildArgs = append(buildArgs, ".")
+		if !jsonOutput {
+			fmt.Println()
+			ui.PrintError("Build failed. Fix the issue above, then retry.")
+		}
+		return fmt.Errorf("%s build failed", b.Name())
+	}
+	return nil
+}
 
-		dockerCmd := exec.Command("docker", buildArgs...)
-		dockerCmd.Stdout = os.Stdout
-		dockerCmd.Stderr = os.Stderr
-		dockerCmd.Stdin = os.Stdin
+// buildOptions merges kyper.yml's build defaults with the build flags.
+// --platform and --target replace the defaults, --build-arg and --secret
+// override entries with the same name, and --cache-from/--cache-to replace
+// the configured caches. Paths in kyper.yml resolve against its directory.
+func buildOptions(kf *config.KyperFile, imageTag string, lookup dotenv.Lookup) (builder.Options, error) {
+	opts := builder.Options{
+		Dockerfile: kf.DockerfilePath(),
+		Context:    kf.ContextDir(),
+		Tag:        imageTag,
+		Platform:   kf.Build.Platform,
+		Target:     kf.Build.Target,
+		Args:       map[string]string{},
+	}
+	if buildPlatform != "" {
+		opts.Platform = buildPlatform
+	}
+	if buildTarget != "" {
+		opts.Target = buildTarget
+	}
 
-		if err := dockerCmd.Run(); err != nil {
-			if !jsonOutput {
-				fmt.Println()
-				ui.PrintError("Build failed. Fix the issue above, then retry.")
+	for k, v := range kf.Build.Args {
+		opts.Args[k] = v
+	}
+	for _, arg := range buildArgs {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			if v, ok = lookup(k); !ok {
+				return opts, fmt.Errorf("--build-arg %s has no value and isn't set in the environment", k)
 			}
-			return fmt.Errorf("docker build failed")
 		}
+		opts.Args[k] = v
+	}
 
-		// 5. Success
-		if jsonOutput {
-			return ui.PrintJSON(map[string]string{
-				"image":  imageTag,
-				"status": "success",
-			})
+	secrets := map[string]string{}
+	var ids []string
+	addSecret := func(spec string) error {
+		id, err := config.SecretID(spec)
+		if err != nil {
+			return err
+		}
+		if _, seen := secrets[id]; !seen {
+			ids = append(ids, id)
 		}
+		secrets[id] = spec
+		return nil
+	}
+	for _, spec := range kf.Build.Secrets {
+		if err := addSecret(builder.ResolvePaths(spec, kf.ResolvePath("."))); err != nil {
+			return opts, err
+		}
+	}
+	for _, spec := range buildSecrets {
+		if err := addSecret(spec); err != nil {
+			return opts, err
+		}
+	}
+	for _, id := range ids {
+		opts.Secrets = append(opts.Secrets, secrets[id])
+	}
 
-		fmt.Println()
-		ui.PrintSuccess(fmt.Sprintf("Build succeeded: %s", imageTag))
-		fmt.Println()
-		ui.PrintInfo("Run: kyper push")
+	opts.CacheFrom = resolveCacheSpecs(kf, kf.Build.CacheFrom, buildCacheFrom)
+	opts.CacheTo = resolveCacheSpecs(kf, kf.Build.CacheTo, buildCacheTo)
+	return opts, nil
+}
 
-		return nil
-	},
+// resolveCacheSpecs returns the cache specs from flags if any were given,
+// otherwise kyper.yml's with local paths resolved.
+func resolveCacheSpecs(kf *config.KyperFile, configured, flags []string) []string {
+	if len(flags) > 0 {
+		return flags
+	}
+	var specs []string
+	for _, spec := range configured {
+		specs = append(specs, builder.ResolvePaths(spec, kf.ResolvePath(".")))
+	}
+	return specs
 }
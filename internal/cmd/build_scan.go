@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/builder"
+	"github.com/bitfootco/kyper-cli/internal/scan"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+)
+
+// scanResult is the outcome of scanning the built image.
+type scanResult struct {
+	Scanner   string         `json:"scanner"`
+	Threshold string         `json:"threshold"`
+	Passed    bool           `json:"passed"`
+	Counts    map[string]int `json:"counts"`
+	// Findings are those at or above Threshold.
+	Findings []scan.Finding `json:"findings"`
+}
+
+// resolveScan checks --scan-severity and finds the scanner, so a typo or a
+// missing scanner fails before the image is built.
+func resolveScan(scanner, threshold string) (name, severity string, err error) {
+	severity = strings.ToUpper(threshold)
+	if !scan.ValidSeverity(severity) {
+		return "", "", fmt.Errorf("invalid --scan-severity %q — use one of: %s", threshold, strings.Join(scan.Severities, ", "))
+	}
+	name, err = scan.Detect(scanner)
+	if err != nil {
+		return "", "", err
+	}
+	return name, severity, nil
+}
+
+// scanImage scans imageTag with the scanner and threshold from resolveScan
+// and applies the severity gate.
+func scanImage(b builder.Builder, imageTag, name, threshold string) (*scanResult, error) {
+	if !jsonOutput {
+		fmt.Println(ui.Bold.Render("— Scan —"))
+	}
+	var report *scan.Report
+	err := ui.RunWithSpinner(fmt.Sprintf("Scanning %s with %s...", imageTag, name), jsonOutput, func() error {
+		var scanErr error
+		report, scanErr = scan.Run(name, b.Name(), imageTag)
+		return scanErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gated := report.AtOrAbove(threshold)
+	return &scanResult{
+		Scanner:   name,
+		Threshold: threshold,
+		Passed:    len(gated) == 0,
+		Counts:    report.Counts(),
+		Findings:  gated,
+	}, nil
+}
+
+// reportScan prints the scan summary and gated findings, and returns an
+// error if the gate failed.
+func reportScan(s *scanResult) error {
+	if !jsonOutput {
+		var counts []string
+		for i := len(scan.Severities) - 1; i >= 0; i-- {
+			sev := scan.Severities[i]
+			counts = append(counts, fmt.Sprintf("%d %s", s.Counts[sev], strings.ToLower(sev)))
+		}
+		summary := strings.Join(counts, ", ")
+
+		if len(s.Findings) > 0 {
+			rows := make([][]string, 0, len(s.Findings))
+			for _, f := range s.Findings {
+				rows = append(rows, []string{f.Severity, f.ID, f.Package, f.Installed, f.FixedIn})
+			}
+			ui.PrintTable([]string{"SEVERITY", "ID", "PACKAGE", "INSTALLED", "FIXED IN"}, rows)
+			fmt.Println()
+		}
+		if s.Passed {
+			ui.PrintSuccess(fmt.Sprintf("%s scan passed (%s)", s.Scanner, summary))
+		} else {
+			ui.PrintError(fmt.Sprintf("%s scan failed: %d finding(s) at or above %s (%s)", s.Scanner, len(s.Findings), s.Threshold, summary))
+		}
+		fmt.Println()
+	}
+	if !s.Passed {
+		return fmt.Errorf("scan failed: %d vulnerability finding(s) at or above %s — Kyper would reject this image", len(s.Findings), s.Threshold)
+	}
+	return nil
+}
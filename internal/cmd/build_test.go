@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/scan"
+)
+
+func TestBuildOptions(t *testing.T) {
+	dir := t.TempDir()
+	kf := &config.KyperFile{
+		Name:   "Invoice Hero",
+		Docker: config.DockerConfig{Dockerfile: "./Dockerfile"},
+		Build: config.BuildConfig{
+			Platform:  "linux/amd64",
+			Target:    "production",
+			Args:      map[string]string{"RUBY_VERSION": "3.3.5", "NODE_ENV": "production"},
+			Secrets:   []string{"id=npmrc,src=.npmrc", "id=token,env=GITHUB_TOKEN"},
+			CacheFrom: []string{"type=local,src=.cache"},
+			CacheTo:   []string{"type=local,dest=.cache"},
+		},
+		Dir: dir,
+	}
+
+	oldPlatform, oldArgs, oldSecrets, oldCacheTo := buildPlatform, buildArgs, buildSecrets, buildCacheTo
+	defer func() {
+		buildPlatform, buildArgs, buildSecrets, buildCacheTo = oldPlatform, oldArgs, oldSecrets, oldCacheTo
+	}()
+	buildPlatform = "linux/arm64"
+	buildArgs = []string{"RUBY_VERSION=3.4.1", "BUNDLE_TOKEN"}
+	buildSecrets = []string{"id=npmrc,src=/ci/npmrc"}
+	buildCacheTo = []string{"ghcr.io/acme/app:cache"}
+
+	lookup := func(k string) (string, bool) {
+		if k == "BUNDLE_TOKEN" {
+			return "from-env", true
+		}
+		return "", false
+	}
+	opts, err := buildOptions(kf, "kyper-local/invoice-hero:1.0.0", lookup)
+	if err != nil {
+		t.Fatalf("buildOptions failed: %v", err)
+	}
+
+	if opts.Platform != "linux/arm64" || opts.Target != "production" {
+		t.Errorf("flags should override kyper.yml platform only, got %q %q", opts.Platform, opts.Target)
+	}
+	wantArgs := map[string]string{"RUBY_VERSION": "3.4.1", "NODE_ENV": "production", "BUNDLE_TOKEN": "from-env"}
+	if !reflect.DeepEqual(opts.Args, wantArgs) {
+		t.Errorf("args = %v, want %v", opts.Args, wantArgs)
+	}
+	wantSecrets := []string{"id=npmrc,src=/ci/npmrc", "id=token,env=GITHUB_TOKEN"}
+	if !reflect.DeepEqual(opts.Secrets, wantSecrets) {
+		t.Errorf("secrets = %v, want %v", opts.Secrets, wantSecrets)
+	}
+	if want := "type=local,src=" + filepath.Join(dir, ".cache"); len(opts.CacheFrom) != 1 || opts.CacheFrom[0] != want {
+		t.Errorf("cache-from = %v, want %s", opts.CacheFrom, want)
+	}
+	if !reflect.DeepEqual(opts.CacheTo, buildCacheTo) {
+		t.Errorf("--cache-to should replace build.cache_to, got %v", opts.CacheTo)
+	}
+
+	buildArgs = []string{"MISSING"}
+	if _, err := buildOptions(kf, "img", lookup); err == nil || !strings.Contains(err.Error(), "isn't set in the environment") {
+		t.Errorf("expected missing build arg error, got %v", err)
+	}
+}
+
+func TestResolveBuilderRejectsUnknown(t *testing.T) {
+	old := builderName
+	defer func() { builderName = old }()
+	builderName = "buildah"
+	if _, err := resolveBuilder(); err == nil || !strings.Contains(err.Error(), `unknown builder "buildah"`) {
+		t.Errorf("expected unknown builder error, got %v", err)
+	}
+}
+
+func TestReportScan(t *testing.T) {
+	old := jsonOutput
+	defer func() { jsonOutput = old }()
+	jsonOutput = true
+
+	if _, _, err := resolveScan("", "severe"); err == nil || !strings.Contains(err.Error(), "invalid --scan-severity") {
+		t.Errorf("expected invalid severity error, got %v", err)
+	}
+	if _, _, err := resolveScan("snyk", "high"); err == nil || !strings.Contains(err.Error(), "unknown scanner") {
+		t.Errorf("expected unknown scanner error, got %v", err)
+	}
+
+	s := &scanResult{Scanner: "trivy", Threshold: "CRITICAL", Passed: true, Counts: map[string]int{"LOW": 2}}
+	if err := reportScan(s); err != nil {
+		t.Errorf("passing scan returned %v", err)
+	}
+	s = &scanResult{Scanner: "trivy", Threshold: "HIGH", Findings: []scan.Finding{{ID: "CVE-1", Severity: "HIGH"}}}
+	if err := reportScan(s); err == nil || !strings.Contains(err.Error(), "1 vulnerability finding(s) at or above HIGH") {
+		t.Errorf("expected gate failure, got %v", err)
+	}
+}
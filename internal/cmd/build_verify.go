@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/builder"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/devenv"
+	"github.com/bitfootco/kyper-cli/internal/smoke"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+)
+
+// verifyResult is the outcome of booting the built image locally.
+type verifyResult struct {
+	Healthy         bool   `json:"healthy"`
+	Path            string `json:"path"`
+	Status          int    `json:"status,omitempty"`
+	TimeToHealthyMS int64  `json:"time_to_healthy_ms,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// verifyImage starts the web process of imageTag with its deps and PORT
+// injected, then polls healthcheck.path at healthcheck.interval until it
+// responds or timeout passes. On failure the container logs are printed.
+func verifyImage(b builder.Builder, kf *config.KyperFile, imageTag string, timeout time.Duration) (*verifyResult, error) {
+	if err := requireCompose(b); err != nil {
+		return nil, err
+	}
+	env, err := loadEnvFile(kf, kf.ResolvePath(".env"), false)
+	if err != nil {
+		return nil, err
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := devenv.New(kf, slugFromTitle(kf.Name), devenv.Options{Image: imageTag, HostPort: port, Env: env, Engine: b.Name()})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = project.Close() }()
+	// Compose progress and logs would corrupt JSON output.
+	if jsonOutput {
+		project.Stdout = os.Stderr
+	}
+
+	check := healthCheck(kf)
+	if !jsonOutput {
+		fmt.Println(ui.Bold.Render("— Verify —"))
+		if kf.Healthcheck.Path == "" {
+			fmt.Println(ui.DimStyle.Render("No healthcheck.path in kyper.yml — checking / instead."))
+		}
+	}
+
+	if err := project.Up(devenv.HookService); err != nil {
+		return nil, err
+	}
+
+	var took time.Duration
+	var res smoke.Result
+	err = ui.RunWithSpinner(fmt.Sprintf("Waiting for GET %s to respond...", check.Path), jsonOutput, func() error {
+		var waitErr error
+		took, res, waitErr = smoke.WaitHealthy(http.DefaultClient, fmt.Sprintf("http://127.0.0.1:%d", port), check, smoke.WaitOptions{
+			Interval: time.Duration(kf.Healthcheck.Interval) * time.Second,
+			Deadline: timeout,
+			Alive: func() error {
+				running, err := project.Running(devenv.HookService)
+				if err == nil && !running {
+					return fmt.Errorf("web process exited during boot")
+				}
+				return nil
+			},
+		})
+		return waitErr
+	})
+
+	v := &verifyResult{Path: check.Path, Status: res.Status}
+	if err != nil {
+		v.Error = err.Error()
+		printVerifyLogs(project)
+		return v, nil
+	}
+	v.Healthy = true
+	v.TimeToHealthyMS = took.Milliseconds()
+	return v, nil
+}
+
+// healthCheck turns kyper.yml's healthcheck into a check, defaulting to /.
+func healthCheck(kf *config.KyperFile) config.SmokeCheck {
+	check := config.SmokeCheck{Name: "healthcheck", Path: kf.Healthcheck.Path, Timeout: kf.Healthcheck.Timeout}
+	if check.Path == "" {
+		check.Path = "/"
+	}
+	return check
+}
+
+func printVerifyLogs(project *devenv.Project) {
+	if !jsonOutput {
+		fmt.Println()
+		fmt.Println(ui.Bold.Render("— Container logs —"))
+	}
+	logs, err := project.Logs(false, devenv.HookService)
+	if err != nil {
+		return
+	}
+	_ = logs.Wait()
+}
+
+func reportVerify(v *verifyResult) error {
+	if !v.Healthy {
+		if !jsonOutput {
+			fmt.Println()
+			ui.PrintError(fmt.Sprintf("App did not become healthy: %s", v.Error))
+		}
+		return fmt.Errorf("verify failed: GET %s %s", v.Path, v.Error)
+	}
+	if !jsonOutput {
+		took := (time.Duration(v.TimeToHealthyMS) * time.Millisecond).Round(100 * time.Millisecond)
+		ui.PrintSuccess(fmt.Sprintf("Healthy after %s (GET %s → %d)", took, v.Path, v.Status))
+		fmt.Println()
+	}
+	return nil
+}
+
+// freePort asks the OS for an unused localhost port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("finding a free port: %w", err)
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
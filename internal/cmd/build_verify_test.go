@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestHealthCheck(t *testing.T) {
+	kf := &config.KyperFile{Healthcheck: config.HealthcheckConfig{Path: "/up", Timeout: 3}}
+	if c := healthCheck(kf); c.Path != "/up" || c.Timeout != 3 {
+		t.Errorf("healthCheck() = %+v", c)
+	}
+	if c := healthCheck(&config.KyperFile{}); c.Path != "/" {
+		t.Errorf("expected / without healthcheck.path, got %q", c.Path)
+	}
+}
+
+func TestReportVerify(t *testing.T) {
+	if err := reportVerify(&verifyResult{Healthy: true, Path: "/up", Status: 200, TimeToHealthyMS: 12300}); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+	err := reportVerify(&verifyResult{Path: "/up", Error: "web process exited during boot"})
+	if err == nil || !strings.Contains(err.Error(), "web process exited during boot") {
+		t.Errorf("expected verify failure, got %v", err)
+	}
+}
+
+func TestFreePort(t *testing.T) {
+	port, err := freePort()
+	if err != nil || port <= 0 {
+		t.Errorf("freePort() = %d, %v", port, err)
+	}
+}
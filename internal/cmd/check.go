@@ -15,7 +15,11 @@ func init() {
 
 var checkCmd = &cobra.Command{
 	Use:   "check",
-	Short: "Validate kyper.yml and confirm Dockerfile exists",
+	Short: "Validate kyper.yml and lint the Dockerfile",
+	Long: `Validate kyper.yml, confirm the Dockerfile exists and lint it for problems
+that matter on Kyper: not listening on $PORT, running as root, unpinned base
+images, unverified remote downloads, copying the whole context without a
+.dockerignore, and a CMD that disagrees with processes.web.`,
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		kf, _, err := loadKyperYML()
@@ -29,10 +33,13 @@ var checkCmd = &cobra.Command{
 		// but we surface it explicitly for the check summary).
 		dockerfileExists := true
 		if kf.Docker.Dockerfile != "" {
-			if _, statErr := os.Stat(kf.Docker.Dockerfile); os.IsNotExist(statErr) {
+			if _, statErr := os.Stat(kf.DockerfilePath()); os.IsNotExist(statErr) {
 				dockerfileExists = false
 			}
 		}
+		if dockerfileExists {
+			kyperfile.LintDockerfile(kf, result)
+		}
 
 		if jsonOutput {
 			out := map[string]interface{}{
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/detect"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var detectExplain bool
+
+func init() {
+	detectCmd.Flags().BoolVar(&detectExplain, "explain", false, "Show which rule fired on which file")
+	rootCmd.AddCommand(detectCmd)
+}
+
+var detectCmd = &cobra.Command{
+	Use:   "detect [dir]",
+	Short: "Show the stack, runtimes, processes and deps kyper init detects",
+	Long: `Runs the detection kyper init uses over a project directory (default: the
+current one) and prints what it found.
+
+Detection is driven by rules: the built-in set plus any *.yml files in
+~/.kyper/detect.d. --explain lists every rule that fired, the file it
+matched, the value it read and the rules file it came from.`,
+	Example: `  kyper detect
+  kyper detect apps/web --explain`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+
+		rules, rulesErr := detect.Default()
+		return runDetect(rules, rulesErr, dir)
+	},
+}
+
+func runDetect(rules *detect.Rules, rulesErr error, dir string) error {
+	warnings := ruleWarnings(rulesErr)
+
+	if detectExplain {
+		matches := rules.Explain(dir)
+		if jsonOutput {
+			if matches == nil {
+				matches = []detect.Match{}
+			}
+			return ui.PrintJSON(map[string]interface{}{
+				"dir":        dir,
+				"matches":    matches,
+				"rule_files": ruleFiles(rules),
+				"warnings":   warnings,
+			})
+		}
+		for _, w := range warnings {
+			ui.PrintWarning(w)
+		}
+		if len(matches) == 0 {
+			ui.PrintWarning("No rules matched in " + dir)
+			return nil
+		}
+		rows := make([][]string, len(matches))
+		for i, m := range matches {
+			origin := m.Origin
+			if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(origin, home) {
+				origin = "~" + strings.TrimPrefix(origin, home)
+			}
+			rows[i] = []string{m.Kind, m.Rule, m.File, m.Matcher, m.Value, origin}
+		}
+		ui.PrintTable([]string{"KIND", "RULE", "FILE", "MATCHER", "VALUE", "FROM"}, rows)
+		return nil
+	}
+
+	d := initDetection{
+		Stacks:    rules.DetectStack(dir),
+		Processes: rules.DetectProcesses(dir),
+		Deps:      rules.DetectDeps(dir),
+	}
+	if jsonOutput {
+		return ui.PrintJSON(map[string]interface{}{
+			"dir":        dir,
+			"detected":   detectionJSON(d),
+			"rule_files": ruleFiles(rules),
+			"warnings":   warnings,
+		})
+	}
+	for _, w := range warnings {
+		ui.PrintWarning(w)
+	}
+	if len(d.Stacks) == 0 && len(d.Processes) == 0 && len(d.Deps) == 0 {
+		ui.PrintWarning("Nothing detected in " + dir)
+		return nil
+	}
+	printDetection(d)
+	fmt.Println(ui.DimStyle.Render("Run 'kyper detect --explain' to see which rules matched."))
+	return nil
+}
+
+// ruleWarnings splits the error from loading user rules into one warning
+// per skipped file.
+func ruleWarnings(err error) []string {
+	warnings := []string{}
+	if err == nil {
+		return warnings
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		warnings = append(warnings, "Skipped detection rules in "+e.Error())
+	}
+	return warnings
+}
+
+func ruleFiles(rules *detect.Rules) []string {
+	if rules.Files == nil {
+		return []string{}
+	}
+	return rules.Files
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/detect"
+)
+
+func TestRunDetectExplainJSON(t *testing.T) {
+	dir := setupRailsProject(t)
+	rules, err := detect.LoadRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origJSON, origExplain := jsonOutput, detectExplain
+	jsonOutput, detectExplain = true, true
+	defer func() { jsonOutput, detectExplain = origJSON, origExplain }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = origStdout }()
+
+	runErr := runDetect(rules, nil, dir)
+	_ = w.Close()
+	os.Stdout = origStdout
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	if runErr != nil {
+		t.Fatalf("runDetect error: %v", runErr)
+	}
+
+	var result struct {
+		Matches []detect.Match `json:"matches"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("parsing JSON output: %v\noutput: %q", err, buf.String())
+	}
+	want := map[string]string{
+		"stack rails":  "config/application.rb",
+		"runtime ruby": ".ruby-version",
+		"process web":  "Procfile",
+		"dep postgres": "Gemfile",
+	}
+	for _, m := range result.Matches {
+		key := m.Kind + " " + m.Rule
+		if file, ok := want[key]; ok {
+			if m.File != file || m.Origin != detect.BuiltinOrigin {
+				t.Errorf("%s matched %s from %s, want %s from the built-in rules", key, m.File, m.Origin, file)
+			}
+			delete(want, key)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing matches %v in %+v", want, result.Matches)
+	}
+}
+
+func TestRuleWarnings(t *testing.T) {
+	_, err := detect.LoadRules(writeBrokenRules(t))
+	warnings := ruleWarnings(err)
+	if len(warnings) != 2 {
+		t.Errorf("expected one warning per broken file, got %v", warnings)
+	}
+	if len(ruleWarnings(nil)) != 0 {
+		t.Error("expected no warnings without an error")
+	}
+}
+
+func writeBrokenRules(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.yml": "stacks: [",
+		"b.yml": "deps:\n  - name: x\n    match: [{file: /etc/passwd}]\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/bitfootco/kyper-cli/internal/builder"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/devenv"
+	"github.com/bitfootco/kyper-cli/internal/dotenv"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	devPort      int
+	devNoBuild   bool
+	devSkipHooks bool
+	devEnvFile   string
+	devAllEnv    bool
+	devKeep      bool
+)
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.Flags().IntVar(&devPort, "port", 3000, "Port on localhost to publish the web process on")
+	devCmd.Flags().BoolVar(&devNoBuild, "no-build", false, "Use the image from the last 'kyper build' instead of rebuilding")
+	devCmd.Flags().BoolVar(&devSkipHooks, "skip-hooks", false, "Don't run the on_deploy hook before starting")
+	devCmd.Flags().StringVar(&devEnvFile, "env-file", ".env", "Path to .env file with values for kyper.yml's env vars")
+	devCmd.Flags().BoolVar(&devAllEnv, "all-env", false, "Pass every var in the env file, not just those declared in kyper.yml")
+	devCmd.Flags().BoolVar(&devKeep, "keep", false, "Leave the containers running on exit")
+	addBuilderFlag(devCmd)
+}
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Run the app locally with its deps, the way Kyper runs it",
+	Long: `Build the image and run every process from kyper.yml locally, together with
+its deps (Postgres, Redis, SeaweedFS for s3, ...) at the versions Kyper
+would use and the env vars Kyper injects (DATABASE_URL, REDIS_URL, AWS_*).
+The on_deploy hook runs first, then logs from all processes stream until
+Ctrl-C, which tears everything down.
+
+Requires Docker with the compose plugin, or Podman or nerdctl with compose
+support.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput {
+			return fmt.Errorf("kyper dev requires interactive mode (remove --json flag)")
+		}
+		b, err := resolveBuilder()
+		if err != nil {
+			return err
+		}
+		if err := requireCompose(b); err != nil {
+			return err
+		}
+
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+		if err := validateForBuild(kf); err != nil {
+			return err
+		}
+
+		envFile := devEnvFile
+		if !cmd.Flags().Changed("env-file") {
+			envFile = kf.ResolvePath(devEnvFile)
+		}
+		env, err := loadEnvFile(kf, envFile, devAllEnv)
+		if err != nil {
+			return err
+		}
+
+		imageTag := localImageTag(kf)
+		if !devNoBuild {
+			if err := buildImage(b, kf, imageTag, false); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+
+		return runDev(kf, devenv.Options{Image: imageTag, HostPort: devPort, Env: env, Engine: b.Name()})
+	},
+}
+
+// requireCompose checks that b's engine can run compose projects.
+func requireCompose(b builder.Builder) error {
+	if err := exec.Command(b.Name(), "compose", "version").Run(); err != nil {
+		if b.Name() == "docker" {
+			return fmt.Errorf("docker compose not available — install the compose plugin: https://docs.docker.com/compose/install/")
+		}
+		return fmt.Errorf("%s compose not available — install compose support for %s", b.Name(), b.Name())
+	}
+	return nil
+}
+
+// loadEnvFile reads an optional env file for local runs and applies
+// kyper.yml's env schema to it.
+func loadEnvFile(kf *config.KyperFile, path string, all bool) (map[string]string, error) {
+	vars, err := dotenv.ReadFile(path, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("loading env file: %w", err)
+	}
+	if !jsonOutput && len(vars) > 0 {
+		fmt.Printf("Loaded %d env var(s) from %s\n", len(vars), path)
+	}
+	return declaredEnv(kf, vars, all, "--all-env")
+}
+
+func runDev(kf *config.KyperFile, opts devenv.Options) error {
+	slug := slugFromTitle(kf.Name)
+	project, err := devenv.New(kf, slug, opts)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C reaches the compose child processes directly; kyper only has to
+	// stay alive long enough to tear the project down.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	defer func() {
+		if devKeep {
+			ui.PrintInfo(fmt.Sprintf("Containers left running. Stop them with: %s compose -p %s down --volumes", project.Engine, project.Name))
+			return
+		}
+		fmt.Println()
+		_ = ui.RunWithSpinner("Stopping containers...", false, project.Close)
+	}()
+
+	if hook := kf.Hooks.OnDeploy; hook != "" && !devSkipHooks {
+		fmt.Println(ui.Bold.Render("— on_deploy —"))
+		fmt.Println(ui.DimStyle.Render("$ " + hook))
+		code, err := project.Run(devenv.HookService, hook)
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("on_deploy hook failed with exit status %d", code)
+		}
+		ui.PrintSuccess("on_deploy hook succeeded")
+		fmt.Println()
+	}
+	if interrupted(sigCh) {
+		return nil
+	}
+
+	if err := project.Up(project.Services...); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if opts.HostPort > 0 {
+		ui.PrintSuccess(fmt.Sprintf("%s is running at http://localhost:%d", kf.Name, opts.HostPort))
+	}
+	fmt.Println(ui.DimStyle.Render("Streaming logs — press Ctrl-C to stop."))
+	fmt.Println()
+
+	logs, err := project.Logs(true, project.Services...)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = logs.Wait()
+		close(done)
+	}()
+	select {
+	case <-sigCh:
+		<-done
+	case <-done:
+	}
+	return nil
+}
+
+// interrupted reports whether a signal arrived without blocking.
+func interrupted(sigCh <-chan os.Signal) bool {
+	select {
+	case <-sigCh:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/dotenv"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+var envImportAll bool
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envListCmd, envSetCmd, envUnsetCmd, envImportCmd, envDiffCmd)
+	envImportCmd.Flags().BoolVar(&envImportAll, "all", false, "Import every var in the file, not just those declared in kyper.yml's env list")
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage your app's production env vars",
+	Long: `Manage the values of the env vars your app needs in production.
+
+kyper.yml's env list names the variables; these commands set their values.
+Values are write-only: once set, Kyper only ever shows them masked.`,
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List env vars set on the app (values masked)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, kf, err := envSetup()
+		if err != nil {
+			return err
+		}
+		return runEnvList(client, slugFromTitle(kf.Name))
+	},
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set KEY=VALUE [KEY=VALUE...]",
+	Short: "Set one or more env vars",
+	Long: `Set one or more env vars on the app. Pass a bare KEY to be prompted for
+its value without echoing it, which keeps secrets out of your shell history.`,
+	Example: `  kyper env set SMTP_URL=smtp://mail.example.com:587
+  kyper env set STRIPE_SECRET_KEY`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, kf, err := envSetup()
+		if err != nil {
+			return err
+		}
+
+		vars := make(map[string]string)
+		for _, arg := range args {
+			if !strings.Contains(arg, "=") && dotenv.ValidKey(arg) {
+				value, err := promptSecret(arg)
+				if err != nil {
+					return err
+				}
+				vars[arg] = value
+				continue
+			}
+			k, v, err := dotenv.ParseAssignment(arg)
+			if err != nil {
+				return err
+			}
+			vars[k] = v
+		}
+		if auto := autoInjectedKeys(vars); len(auto) > 0 {
+			return fmt.Errorf("%s auto-injected by Kyper and cannot be set", strings.Join(auto, ", "))
+		}
+		if undeclared := undeclaredKeys(vars, kf.EnvNames()); len(undeclared) > 0 && !jsonOutput {
+			ui.PrintWarning(fmt.Sprintf("Not declared in kyper.yml's env list: %s", strings.Join(undeclared, ", ")))
+		}
+		if err := checkEnvSchema(kf, vars); err != nil {
+			return err
+		}
+
+		return runEnvSet(client, slugFromTitle(kf.Name), vars)
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset KEY [KEY...]",
+	Short: "Remove one or more env vars",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, kf, err := envSetup()
+		if err != nil {
+			return err
+		}
+
+		if !jsonOutput {
+			var confirm bool
+			err := huh.NewConfirm().
+				Title(fmt.Sprintf("Remove %s?", strings.Join(args, ", "))).
+				Description("Running installs keep their current values until they are redeployed.").
+				Affirmative("Yes, remove").
+				Negative("No, keep").
+				Value(&confirm).
+				Run()
+			if err != nil {
+				return err
+			}
+			if !confirm {
+				fmt.Println("Cancelled.")
+				return nil
+			}
+		}
+
+		return runEnvUnset(client, slugFromTitle(kf.Name), args)
+	},
+}
+
+var envImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Set env vars from a .env file",
+	Long: `Set env vars from a .env file. Only the variables declared in kyper.yml's
+env list are imported unless --all is given; vars Kyper injects itself
+(DATABASE_URL, PORT, ...) are always skipped.`,
+	Example: `  kyper env import .env.production`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, kf, err := envSetup()
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(args[0]); err != nil {
+			return fmt.Errorf("reading env file: %w", err)
+		}
+		vars, err := dotenv.ReadFile(args[0], os.LookupEnv)
+		if err != nil {
+			return fmt.Errorf("loading env file: %w", err)
+		}
+
+		for _, k := range autoInjectedKeys(vars) {
+			delete(vars, k)
+		}
+		selected, dropped := selectEnv(vars, kf.EnvNames(), envImportAll)
+		if len(dropped) > 0 && !jsonOutput {
+			ui.PrintWarning(fmt.Sprintf("Skipping %d var(s) not declared in kyper.yml: %s (use --all to import them)",
+				len(dropped), strings.Join(dropped, ", ")))
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("nothing to import from %s", args[0])
+		}
+		if err := checkEnvSchema(kf, selected); err != nil {
+			return err
+		}
+
+		return runEnvSet(client, slugFromTitle(kf.Name), selected)
+	},
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare kyper.yml's env list with the vars set on the app",
+	Long: `Compare the env vars declared in kyper.yml with the ones set on the app.
+Exits non-zero if a required var has no value; vars marked required: false
+or with a default are reported as optional.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, kf, err := envSetup()
+		if err != nil {
+			return err
+		}
+		return runEnvDiff(client, slugFromTitle(kf.Name), kf.Env)
+	},
+}
+
+func envSetup() (*api.Client, *config.KyperFile, error) {
+	_, client, err := requireAuth()
+	if err != nil {
+		return nil, nil, err
+	}
+	kf, _, err := loadKyperYML()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, kf, nil
+}
+
+// envAPIError turns a missing app into a hint; env vars live on the app, so
+// it has to have been pushed at least once.
+func envAPIError(action string, err error) error {
+	if api.IsNotFound(err) {
+		return fmt.Errorf("app not found on Kyper — run 'kyper push' first")
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+func promptSecret(key string) (string, error) {
+	if jsonOutput {
+		return "", fmt.Errorf("%s has no value — pass %s=VALUE with --json", key, key)
+	}
+	var value string
+	err := huh.NewInput().
+		Title(fmt.Sprintf("Value for %s", key)).
+		EchoMode(huh.EchoModePassword).
+		Value(&value).
+		Run()
+	return value, err
+}
+
+// checkEnvSchema rejects values that don't satisfy kyper.yml's env schema.
+// Vars that aren't being set are not reported as missing here.
+func checkEnvSchema(kf *config.KyperFile, vars map[string]string) error {
+	result := kyperfile.CheckEnvValues(kf, vars)
+	if !result.Valid {
+		return fmt.Errorf("invalid env values:\n  %s", strings.Join(result.Errors, "\n  "))
+	}
+	return nil
+}
+
+func autoInjectedKeys(vars map[string]string) []string {
+	var keys []string
+	for _, k := range kyperfile.AutoInjectedEnv {
+		if _, ok := vars[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func undeclaredKeys(vars map[string]string, declared []string) []string {
+	_, dropped := selectEnv(vars, declared, false)
+	return dropped
+}
+
+func runEnvList(client *api.Client, slug string) error {
+	var vars []api.EnvVar
+	err := ui.RunWithSpinner("Fetching env vars...", jsonOutput, func() error {
+		var listErr error
+		vars, listErr = client.ListEnvVars(slug)
+		return listErr
+	})
+	if err != nil {
+		return envAPIError("listing env vars", err)
+	}
+
+	if jsonOutput {
+		if vars == nil {
+			vars = []api.EnvVar{}
+		}
+		return ui.PrintJSON(map[string]interface{}{"env_vars": vars})
+	}
+
+	if len(vars) == 0 {
+		ui.PrintWarning("No env vars set. Add them with 'kyper env set' or 'kyper env import'.")
+		return nil
+	}
+
+	rows := make([][]string, len(vars))
+	for i, v := range vars {
+		rows[i] = []string{v.Key, v.Value, v.UpdatedAt}
+	}
+	ui.PrintTable([]string{"KEY", "VALUE", "UPDATED"}, rows)
+	return nil
+}
+
+func runEnvSet(client *api.Client, slug string, vars map[string]string) error {
+	var updated []api.EnvVar
+	err := ui.RunWithSpinner("Saving env vars...", jsonOutput, func() error {
+		var setErr error
+		updated, setErr = client.SetEnvVars(slug, vars)
+		return setErr
+	})
+	if err != nil {
+		return envAPIError("setting env vars", err)
+	}
+
+	if jsonOutput {
+		if updated == nil {
+			updated = []api.EnvVar{}
+		}
+		return ui.PrintJSON(map[string]interface{}{"env_vars": updated})
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ui.PrintSuccess(fmt.Sprintf("Set %d env var(s): %s", len(keys), strings.Join(keys, ", ")))
+	return nil
+}
+
+func runEnvUnset(client *api.Client, slug string, keys []string) error {
+	for _, k := range keys {
+		if _, err := client.UnsetEnvVar(slug, k); err != nil {
+			if api.IsNotFound(err) {
+				return fmt.Errorf("env var %s is not set (or the app hasn't been pushed yet)", k)
+			}
+			return fmt.Errorf("removing %s: %w", k, err)
+		}
+	}
+
+	if jsonOutput {
+		return ui.PrintJSON(map[string]interface{}{"removed": keys})
+	}
+	ui.PrintSuccess(fmt.Sprintf("Removed %s", strings.Join(keys, ", ")))
+	return nil
+}
+
+// envDiff compares declared entries with the keys set remotely. Auto-injected
+// names are never expected to be set; unset entries that aren't required or
+// have a default are optional rather than missing.
+type envDiff struct {
+	Set        []string `json:"set"`
+	Missing    []string `json:"missing"`
+	Optional   []string `json:"optional"`
+	Undeclared []string `json:"undeclared"`
+}
+
+func diffEnv(declared []config.EnvEntry, remote []api.EnvVar) envDiff {
+	isSet := make(map[string]bool)
+	for _, v := range remote {
+		isSet[v.Key] = true
+	}
+	autoInjected := make(map[string]bool)
+	for _, e := range kyperfile.AutoInjectedEnv {
+		autoInjected[e] = true
+	}
+
+	d := envDiff{Set: []string{}, Missing: []string{}, Optional: []string{}, Undeclared: []string{}}
+	isDeclared := make(map[string]bool)
+	for _, e := range declared {
+		name := e.Name
+		if name == "" || autoInjected[name] || isDeclared[name] {
+			continue
+		}
+		isDeclared[name] = true
+		switch {
+		case isSet[name]:
+			d.Set = append(d.Set, name)
+		case !e.IsRequired() || e.Default != "":
+			d.Optional = append(d.Optional, name)
+		default:
+			d.Missing = append(d.Missing, name)
+		}
+	}
+	for _, v := range remote {
+		if !isDeclared[v.Key] {
+			d.Undeclared = append(d.Undeclared, v.Key)
+		}
+	}
+	sort.Strings(d.Set)
+	sort.Strings(d.Missing)
+	sort.Strings(d.Optional)
+	sort.Strings(d.Undeclared)
+	return d
+}
+
+func runEnvDiff(client *api.Client, slug string, declared []config.EnvEntry) error {
+	var remote []api.EnvVar
+	err := ui.RunWithSpinner("Fetching env vars...", jsonOutput, func() error {
+		var listErr error
+		remote, listErr = client.ListEnvVars(slug)
+		return listErr
+	})
+	if err != nil {
+		return envAPIError("listing env vars", err)
+	}
+
+	d := diffEnv(declared, remote)
+	if jsonOutput {
+		if err := ui.PrintJSON(d); err != nil {
+			return err
+		}
+	} else {
+		var rows [][]string
+		for _, k := range d.Set {
+			rows = append(rows, []string{k, ui.Success.Render("set")})
+		}
+		for _, k := range d.Missing {
+			rows = append(rows, []string{k, ui.Error.Render("missing")})
+		}
+		for _, k := range d.Optional {
+			rows = append(rows, []string{k, ui.DimStyle.Render("optional, not set")})
+		}
+		for _, k := range d.Undeclared {
+			rows = append(rows, []string{k, ui.Warning.Render("not in kyper.yml")})
+		}
+		if len(rows) == 0 {
+			ui.PrintInfo("kyper.yml declares no env vars and none are set.")
+			return nil
+		}
+		ui.PrintTable([]string{"KEY", "STATUS"}, rows)
+	}
+
+	if len(d.Missing) > 0 {
+		return fmt.Errorf("%d required env var(s) not set — run 'kyper env set %s'", len(d.Missing), d.Missing[0])
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestDiffEnv(t *testing.T) {
+	declared := envEntries("STRIPE_KEY", "SMTP_URL", "DATABASE_URL", "STRIPE_KEY")
+	remote := []api.EnvVar{{Key: "STRIPE_KEY"}, {Key: "LEGACY_TOKEN"}}
+
+	d := diffEnv(declared, remote)
+	if strings.Join(d.Set, ",") != "STRIPE_KEY" {
+		t.Errorf("Set = %v", d.Set)
+	}
+	if strings.Join(d.Missing, ",") != "SMTP_URL" {
+		t.Errorf("Missing = %v (DATABASE_URL is auto-injected)", d.Missing)
+	}
+	if strings.Join(d.Undeclared, ",") != "LEGACY_TOKEN" {
+		t.Errorf("Undeclared = %v", d.Undeclared)
+	}
+}
+
+func TestDiffEnvOptional(t *testing.T) {
+	optional := false
+	declared := []config.EnvEntry{
+		{Name: "STRIPE_KEY"},
+		{Name: "SENTRY_DSN", Required: &optional},
+		{Name: "LOG_LEVEL", Default: "info"},
+		{Name: "FEATURE_FLAGS", Required: &optional},
+	}
+	remote := []api.EnvVar{{Key: "FEATURE_FLAGS"}}
+
+	d := diffEnv(declared, remote)
+	if strings.Join(d.Missing, ",") != "STRIPE_KEY" {
+		t.Errorf("Missing = %v, want only the required var", d.Missing)
+	}
+	if strings.Join(d.Optional, ",") != "LOG_LEVEL,SENTRY_DSN" {
+		t.Errorf("Optional = %v", d.Optional)
+	}
+	if strings.Join(d.Set, ",") != "FEATURE_FLAGS" {
+		t.Errorf("Set = %v", d.Set)
+	}
+}
+
+func envEntries(names ...string) []config.EnvEntry {
+	entries := make([]config.EnvEntry, len(names))
+	for i, n := range names {
+		entries[i] = config.EnvEntry{Name: n}
+	}
+	return entries
+}
+
+func TestAutoInjectedKeys(t *testing.T) {
+	got := autoInjectedKeys(map[string]string{"PORT": "3000", "STRIPE_KEY": "x", "DATABASE_URL": "y"})
+	if strings.Join(got, ",") != "DATABASE_URL,PORT" {
+		t.Errorf("autoInjectedKeys() = %v", got)
+	}
+}
+
+func TestRunEnvDiffFailsOnMissing(t *testing.T) {
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"env_vars": []api.EnvVar{{Key: "STRIPE_KEY", Value: "••••"}},
+		})
+	}))
+	defer srv.Close()
+
+	if err := runEnvDiff(client, "my-app", envEntries("STRIPE_KEY")); err != nil {
+		t.Errorf("expected no error when everything is set, got %v", err)
+	}
+	optional := false
+	withOptional := []config.EnvEntry{{Name: "STRIPE_KEY"}, {Name: "SENTRY_DSN", Required: &optional}, {Name: "LOG_LEVEL", Default: "info"}}
+	if err := runEnvDiff(client, "my-app", withOptional); err != nil {
+		t.Errorf("optional vars shouldn't fail the diff, got %v", err)
+	}
+	err := runEnvDiff(client, "my-app", envEntries("STRIPE_KEY", "SMTP_URL"))
+	if err == nil || !strings.Contains(err.Error(), "1 required env var(s) not set") {
+		t.Errorf("expected missing var error, got %v", err)
+	}
+}
+
+func TestRunEnvSetAppNotFound(t *testing.T) {
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}))
+	defer srv.Close()
+
+	err := runEnvSet(client, "my-app", map[string]string{"SMTP_URL": "smtp://x"})
+	if err == nil || !strings.Contains(err.Error(), "kyper push") {
+		t.Errorf("expected push hint, got %v", err)
+	}
+}
+
+func TestRunEnvUnset(t *testing.T) {
+	var deleted []string
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/v1/apps/my-app/env_vars/"))
+		_ = json.NewEncoder(w).Encode(api.MessageResponse{Message: "removed"})
+	}))
+	defer srv.Close()
+
+	if err := runEnvUnset(client, "my-app", []string{"A", "B"}); err != nil {
+		t.Fatalf("runEnvUnset failed: %v", err)
+	}
+	if strings.Join(deleted, ",") != "A,B" {
+		t.Errorf("deleted = %v", deleted)
+	}
+}
@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"time"
 
 	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/archive"
 	"github.com/bitfootco/kyper-cli/internal/config"
 	"github.com/bitfootco/kyper-cli/internal/ui"
+	"gopkg.in/yaml.v3"
 )
 
 const defaultBaseURL = "https://kyper.shop"
@@ -38,8 +44,16 @@ func requireAuth() (*config.Config, *api.Client, error) {
 	return cfg, client, nil
 }
 
+// kyperFilePath returns the kyper.yml path selected with --file.
+func kyperFilePath() string {
+	if fileFlag == "" {
+		return "kyper.yml"
+	}
+	return fileFlag
+}
+
 func loadKyperYML() (*config.KyperFile, []byte, error) {
-	kf, raw, err := config.LoadKyperFile("kyper.yml")
+	kf, raw, err := config.LoadKyperFile(kyperFilePath())
 	if err != nil {
 		return nil, nil, fmt.Errorf("reading kyper.yml: %w\nRun 'kyper init' to create one", err)
 	}
@@ -61,6 +75,70 @@ func slugifyYAMLName(raw []byte, slug string) []byte {
 	return yamlNameRegexp.ReplaceAll(raw, []byte("name: "+slug))
 }
 
+// uploadYAML prepares raw kyper.yml for the API: the name is slugified and,
+// when build.context widens the archive beyond kyper.yml's directory,
+// docker.dockerfile is rewritten relative to the archive root.
+func uploadYAML(raw []byte, slug string, kf *config.KyperFile) ([]byte, error) {
+	out := slugifyYAMLName(raw, slug)
+	if kf.Build.Context == "" {
+		return out, nil
+	}
+	dockerfile, err := kf.DockerfileInContext()
+	if err != nil {
+		return nil, err
+	}
+	return setYAMLDockerfile(out, dockerfile)
+}
+
+// setYAMLDockerfile sets docker.dockerfile in raw kyper.yml, adding the key
+// if it's missing. Other keys named dockerfile are left alone.
+func setYAMLDockerfile(raw []byte, dockerfile string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parsing kyper.yml: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing kyper.yml: expected a mapping at the top level")
+	}
+	docker := yamlMappingValue(doc.Content[0], "docker")
+	if docker == nil {
+		docker = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		doc.Content[0].Content = append(doc.Content[0].Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "docker"}, docker)
+	}
+	if docker.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing kyper.yml: docker must be a mapping")
+	}
+	if value := yamlMappingValue(docker, "dockerfile"); value != nil {
+		value.Kind, value.Tag, value.Style, value.Value = yaml.ScalarNode, "!!str", 0, dockerfile
+	} else {
+		docker.Content = append(docker.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "dockerfile"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: dockerfile})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlMappingValue returns the value node for key in mapping, or nil.
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
 // tailLog streams the build log for a version, printing output as it arrives.
 // It returns the final build status (e.g. "built", "build_failed", "in_review").
 func tailLog(client *api.Client, versionID int, startCursor int) (string, error) {
@@ -150,40 +228,115 @@ func printBuildStatus(status string) {
 // metadata if it does. Returns a wrapped error on failure.
 func syncApp(client *api.Client, slug string, kf *config.KyperFile) error {
 	return ui.RunWithSpinner("Syncing app...", jsonOutput, func() error {
-		_, statusErr := client.GetAppStatus(slug)
-		if statusErr != nil {
-			if api.IsNotFound(statusErr) {
-				_, createErr := client.CreateApp(buildAppParams(kf))
-				return createErr
-			}
-			return statusErr
+		return syncAppMetadata(client, slug, kf)
+	})
+}
+
+// syncAppMetadata is syncApp without the spinner.
+func syncAppMetadata(client *api.Client, slug string, kf *config.KyperFile) error {
+	_, statusErr := client.GetAppStatus(slug)
+	if statusErr != nil {
+		if api.IsNotFound(statusErr) {
+			_, createErr := client.CreateApp(buildAppParams(kf))
+			return createErr
 		}
-		_, updateErr := client.UpdateApp(slug, buildUpdateParams(kf))
-		return updateErr
+		return statusErr
+	}
+	_, updateErr := client.UpdateApp(slug, buildUpdateParams(kf))
+	return updateErr
+}
+
+// resolveArchiveFormat picks the upload archive format. An explicit override
+// must be one the server advertises (zip is always accepted); otherwise the
+// format is negotiated from the server's capabilities, falling back to zip
+// when the server has no capabilities endpoint. Any other error, such as a
+// 401 or a network failure, is returned rather than mistaken for zip-only.
+func resolveArchiveFormat(client *api.Client, override string) (archive.Format, error) {
+	var advertised []string
+	caps, err := client.GetCapabilities()
+	switch {
+	case err == nil:
+		advertised = caps.ArchiveFormats
+	case !capabilitiesUnsupported(err):
+		return "", fmt.Errorf("fetching server capabilities: %w", err)
+	}
+
+	if override == "" {
+		return archive.Negotiate(advertised), nil
+	}
+	format, err := archive.ParseFormat(override)
+	if err != nil {
+		return "", err
+	}
+	if !archive.Supports(advertised, format) {
+		return "", fmt.Errorf("server does not accept %s archives — use --archive-format zip", format)
+	}
+	return format, nil
+}
+
+// capabilitiesUnsupported reports whether err means the server predates the
+// capabilities endpoint.
+func capabilitiesUnsupported(err error) bool {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// buildArchive archives kf's build context at archivePath behind a spinner,
+// then reports its size and any entries the archiver skipped.
+func buildArchive(kf *config.KyperFile, archivePath string, format archive.Format) error {
+	var result *archive.Result
+	err := ui.RunWithSpinner("Building archive...", jsonOutput, func() error {
+		var archiveErr error
+		result, archiveErr = createAppArchive(kf, archivePath, format)
+		return archiveErr
 	})
+	if err != nil {
+		return fmt.Errorf("building archive: %w", err)
+	}
+	if jsonOutput {
+		return nil
+	}
+
+	for _, w := range result.Warnings {
+		ui.PrintWarning(w)
+	}
+	if info, statErr := os.Stat(archivePath); statErr == nil {
+		fmt.Printf("Archive: %s (%s)\n", humanizeBytes(info.Size()), format)
+	}
+	return nil
 }
 
-// parseEnvFile reads a .env-style file and returns a map of key→value pairs.
-// Blank lines and lines starting with # are skipped. Lines without = are skipped.
-// Returns an empty map (not an error) if the file doesn't exist.
-func parseEnvFile(path string) map[string]string {
-	out := map[string]string{}
-	data, err := os.ReadFile(path)
+// createAppArchive archives kf's build context at archivePath.
+func createAppArchive(kf *config.KyperFile, archivePath string, format archive.Format) (*archive.Result, error) {
+	contextDir, opts, err := archiveContext(kf)
 	if err != nil {
-		return out
+		return nil, err
 	}
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		k, v, ok := strings.Cut(line, "=")
-		if !ok {
-			continue
-		}
-		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	opts.Format = format
+	return archive.CreateWithOptions(contextDir, archivePath, opts)
+}
+
+// archiveContext returns the absolute build context for kf and the options
+// that decide what gets archived from it. When the context is wider than
+// kyper.yml's directory, that directory's .kyperignore still applies to its
+// subtree.
+func archiveContext(kf *config.KyperFile) (string, archive.Options, error) {
+	contextDir, err := filepath.Abs(kf.ContextDir())
+	if err != nil {
+		return "", archive.Options{}, fmt.Errorf("resolving build context: %w", err)
+	}
+	var opts archive.Options
+	if appDir, absErr := filepath.Abs(kf.ResolvePath(".")); absErr == nil && appDir != contextDir {
+		opts.IgnoreDirs = []string{appDir}
 	}
-	return out
+	return contextDir, opts, nil
 }
 
 func openBrowser(url string) error {
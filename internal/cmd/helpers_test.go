@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 
 	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/archive"
+	"github.com/bitfootco/kyper-cli/internal/config"
 )
 
 func testAPIClient(handler http.Handler) (*api.Client, *httptest.Server) {
@@ -118,75 +120,6 @@ func TestSlugifyYAMLName(t *testing.T) {
 	}
 }
 
-func TestParseEnvFile(t *testing.T) {
-	t.Run("missing file returns empty map", func(t *testing.T) {
-		got := parseEnvFile("/nonexistent/.env")
-		if len(got) != 0 {
-			t.Errorf("expected empty map, got %v", got)
-		}
-	})
-
-	t.Run("empty file returns empty map", func(t *testing.T) {
-		dir := t.TempDir()
-		path := filepath.Join(dir, ".env")
-		_ = os.WriteFile(path, []byte(""), 0644)
-		got := parseEnvFile(path)
-		if len(got) != 0 {
-			t.Errorf("expected empty map, got %v", got)
-		}
-	})
-
-	t.Run("valid key=value pairs", func(t *testing.T) {
-		dir := t.TempDir()
-		path := filepath.Join(dir, ".env")
-		content := "MY_KEY=hello\nOTHER_KEY=world\n"
-		_ = os.WriteFile(path, []byte(content), 0644)
-		got := parseEnvFile(path)
-		if got["MY_KEY"] != "hello" {
-			t.Errorf("expected MY_KEY=hello, got %q", got["MY_KEY"])
-		}
-		if got["OTHER_KEY"] != "world" {
-			t.Errorf("expected OTHER_KEY=world, got %q", got["OTHER_KEY"])
-		}
-	})
-
-	t.Run("comments and blank lines are skipped", func(t *testing.T) {
-		dir := t.TempDir()
-		path := filepath.Join(dir, ".env")
-		content := "# this is a comment\n\nMY_KEY=hello\n# another comment\n"
-		_ = os.WriteFile(path, []byte(content), 0644)
-		got := parseEnvFile(path)
-		if len(got) != 1 {
-			t.Errorf("expected 1 entry, got %d: %v", len(got), got)
-		}
-		if got["MY_KEY"] != "hello" {
-			t.Errorf("expected MY_KEY=hello, got %q", got["MY_KEY"])
-		}
-	})
-
-	t.Run("lines without = are skipped", func(t *testing.T) {
-		dir := t.TempDir()
-		path := filepath.Join(dir, ".env")
-		content := "NOEQUALS\nMY_KEY=hello\n"
-		_ = os.WriteFile(path, []byte(content), 0644)
-		got := parseEnvFile(path)
-		if len(got) != 1 {
-			t.Errorf("expected 1 entry, got %d: %v", len(got), got)
-		}
-	})
-
-	t.Run("values with = in them are preserved", func(t *testing.T) {
-		dir := t.TempDir()
-		path := filepath.Join(dir, ".env")
-		content := "MY_KEY=val=ue\n"
-		_ = os.WriteFile(path, []byte(content), 0644)
-		got := parseEnvFile(path)
-		if got["MY_KEY"] != "val=ue" {
-			t.Errorf("expected MY_KEY=val=ue, got %q", got["MY_KEY"])
-		}
-	})
-}
-
 func TestSlugFromTitle(t *testing.T) {
 	tests := []struct {
 		input string
@@ -211,3 +144,109 @@ func TestSlugFromTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveArchiveFormat(t *testing.T) {
+	advertised := []string{"tar.zst", "zip"}
+	status := http.StatusOK
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+		if advertised == nil {
+			w.WriteHeader(404)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"archive_formats": advertised})
+	}))
+	defer srv.Close()
+
+	format, err := resolveArchiveFormat(client, "")
+	if err != nil {
+		t.Fatalf("resolveArchiveFormat failed: %v", err)
+	}
+	if format != archive.FormatZip {
+		t.Errorf("expected zip to stay the default while the server lists it, got %q", format)
+	}
+
+	format, err = resolveArchiveFormat(client, "tar.zst")
+	if err != nil || format != archive.FormatTarZst {
+		t.Errorf("expected tar.zst override to be honored, got %q (%v)", format, err)
+	}
+
+	if _, err := resolveArchiveFormat(client, "tar.gz"); err == nil {
+		t.Error("expected error for format the server doesn't advertise")
+	}
+
+	// Servers that no longer accept zip get their first listed format.
+	advertised = []string{"tar.zst"}
+	format, err = resolveArchiveFormat(client, "")
+	if err != nil || format != archive.FormatTarZst {
+		t.Errorf("expected tar.zst when zip isn't listed, got %q (%v)", format, err)
+	}
+
+	// Older servers without a capabilities endpoint only accept zip.
+	advertised = nil
+	format, err = resolveArchiveFormat(client, "")
+	if err != nil || format != archive.FormatZip {
+		t.Errorf("expected zip fallback, got %q (%v)", format, err)
+	}
+
+	// Other failures surface instead of looking like a zip-only server.
+	status = http.StatusUnauthorized
+	if _, err := resolveArchiveFormat(client, "tar.zst"); err == nil || !strings.Contains(err.Error(), "fetching server capabilities") || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the 401 to be reported, got %v", err)
+	}
+}
+
+func TestUploadYAMLRewritesDockerfileForContext(t *testing.T) {
+	raw := []byte("name: My App\ndocker:\n  dockerfile: ./Dockerfile\nbuild:\n  context: ../..\n")
+	kf := &config.KyperFile{
+		Dir:    filepath.Join("apps", "web"),
+		Docker: config.DockerConfig{Dockerfile: "./Dockerfile"},
+		Build:  config.BuildConfig{Context: "../.."},
+	}
+
+	got, err := uploadYAML(raw, "my-app", kf)
+	if err != nil {
+		t.Fatalf("uploadYAML failed: %v", err)
+	}
+	want := "name: my-app\ndocker:\n  dockerfile: apps/web/Dockerfile\nbuild:\n  context: ../..\n"
+	if string(got) != want {
+		t.Errorf("uploadYAML() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Only docker.dockerfile is rewritten, even when other keys share its
+	// name, and comments survive.
+	raw2 := []byte("# My app\nname: My App\nbuild:\n  context: ../..\nassets:\n  dockerfile: ./assets.Dockerfile\ndocker:\n  dockerfile: ./Dockerfile # main image\n")
+	got, err = uploadYAML(raw2, "my-app", kf)
+	if err != nil {
+		t.Fatalf("uploadYAML failed: %v", err)
+	}
+	want = "# My app\nname: my-app\nbuild:\n  context: ../..\nassets:\n  dockerfile: ./assets.Dockerfile\ndocker:\n  dockerfile: apps/web/Dockerfile # main image\n"
+	if string(got) != want {
+		t.Errorf("uploadYAML() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// A kyper.yml without a docker section gets one.
+	got, err = uploadYAML([]byte("name: My App\nbuild:\n  context: ../..\n"), "my-app", kf)
+	if err != nil {
+		t.Fatalf("uploadYAML failed: %v", err)
+	}
+	want = "name: my-app\nbuild:\n  context: ../..\ndocker:\n  dockerfile: apps/web/Dockerfile\n"
+	if string(got) != want {
+		t.Errorf("uploadYAML() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without build.context the file is only slugified.
+	kf.Build.Context = ""
+	got, err = uploadYAML(raw, "my-app", kf)
+	if err != nil {
+		t.Fatalf("uploadYAML failed: %v", err)
+	}
+	if string(got) != string(slugifyYAMLName(raw, "my-app")) {
+		t.Errorf("expected only the name to change, got:\n%s", got)
+	}
+}
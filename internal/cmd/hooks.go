@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/builder"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/devenv"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var hooksEnvFile string
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksRunCmd)
+	hooksRunCmd.Flags().StringVar(&hooksEnvFile, "env-file", ".env", "Path to .env file with values for kyper.yml's env vars")
+	addBuilderFlag(hooksRunCmd)
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Run lifecycle hooks from kyper.yml locally",
+}
+
+var hooksRunCmd = &cobra.Command{
+	Use:   "run <on_deploy|on_update>",
+	Short: "Run a hook against the locally built image and fresh deps",
+	Long: `Start the image from 'kyper build' with the deps declared in kyper.yml and
+run a hook in it, the way Kyper does after a deploy or update. Reports the
+hook's exit status and duration; everything is removed afterwards.`,
+	Example:   `  kyper build && kyper hooks run on_deploy`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"on_deploy", "on_update"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+		command, ok := hookCommand(kf, args[0])
+		if !ok {
+			return fmt.Errorf("unknown hook %q — use on_deploy or on_update", args[0])
+		}
+		if command == "" {
+			return fmt.Errorf("kyper.yml doesn't declare hooks.%s", args[0])
+		}
+		b, err := resolveBuilder()
+		if err != nil {
+			return err
+		}
+		if err := requireCompose(b); err != nil {
+			return err
+		}
+
+		imageTag := localImageTag(kf)
+		if !b.ImageExists(imageTag) {
+			return fmt.Errorf("image %s not found — run 'kyper build' first", imageTag)
+		}
+
+		envFile := hooksEnvFile
+		if !cmd.Flags().Changed("env-file") {
+			envFile = kf.ResolvePath(hooksEnvFile)
+		}
+		env, err := loadEnvFile(kf, envFile, false)
+		if err != nil {
+			return err
+		}
+
+		results, err := runHooks(b, kf, imageTag, env, []string{args[0]})
+		if err != nil {
+			return err
+		}
+		hookErr := reportHooks(results)
+		if jsonOutput {
+			if err := ui.PrintJSON(map[string]interface{}{"hooks": results}); err != nil {
+				return err
+			}
+		}
+		return hookErr
+	},
+}
+
+// hookResult is the outcome of running one hook locally.
+type hookResult struct {
+	Hook       string `json:"hook"`
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// hookCommand returns the command for a hook name and whether the name is
+// a known hook.
+func hookCommand(kf *config.KyperFile, name string) (string, bool) {
+	switch name {
+	case "on_deploy":
+		return kf.Hooks.OnDeploy, true
+	case "on_update":
+		return kf.Hooks.OnUpdate, true
+	}
+	return "", false
+}
+
+// declaredHooks lists the hooks kf defines, in the order Kyper runs them on
+// a fresh install followed by an update.
+func declaredHooks(kf *config.KyperFile) []string {
+	var hooks []string
+	for _, name := range []string{"on_deploy", "on_update"} {
+		if cmd, _ := hookCommand(kf, name); cmd != "" {
+			hooks = append(hooks, name)
+		}
+	}
+	return hooks
+}
+
+// runHooks runs the named hooks in order against one set of fresh deps,
+// stopping at the first failure. Hook output goes to stdout, or to stderr
+// in JSON mode so the report stays parseable.
+func runHooks(b builder.Builder, kf *config.KyperFile, imageTag string, env map[string]string, hooks []string) ([]hookResult, error) {
+	project, err := devenv.New(kf, slugFromTitle(kf.Name), devenv.Options{Image: imageTag, Env: env, Engine: b.Name()})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = project.Close() }()
+	if jsonOutput {
+		project.Stdout = os.Stderr
+	}
+
+	var results []hookResult
+	for _, name := range hooks {
+		command, _ := hookCommand(kf, name)
+		if !jsonOutput {
+			fmt.Println(ui.Bold.Render("— " + name + " —"))
+			fmt.Println(ui.DimStyle.Render("$ " + command))
+		}
+
+		start := time.Now()
+		code, err := project.Run(devenv.HookService, command)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, hookResult{
+			Hook:       name,
+			Command:    command,
+			ExitCode:   code,
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+		if code != 0 {
+			break
+		}
+	}
+	return results, nil
+}
+
+// reportHooks prints hook results and returns an error if a hook failed.
+// In JSON mode the caller includes the results in its own output.
+func reportHooks(results []hookResult) error {
+	var failed *hookResult
+	for i, r := range results {
+		if r.ExitCode != 0 {
+			failed = &results[i]
+		}
+		if jsonOutput {
+			continue
+		}
+		took := (time.Duration(r.DurationMS) * time.Millisecond).Round(100 * time.Millisecond).String()
+		if r.ExitCode == 0 {
+			ui.PrintSuccess(fmt.Sprintf("%s succeeded in %s", r.Hook, took))
+		} else {
+			ui.PrintError(fmt.Sprintf("%s failed with exit status %d after %s", r.Hook, r.ExitCode, took))
+		}
+	}
+
+	if failed != nil {
+		return fmt.Errorf("%s hook failed with exit status %d", failed.Hook, failed.ExitCode)
+	}
+	return nil
+}
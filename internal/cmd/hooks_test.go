@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestDeclaredHooks(t *testing.T) {
+	kf := &config.KyperFile{Hooks: config.HooksConfig{OnUpdate: "bin/rails db:migrate"}}
+	if got := declaredHooks(kf); strings.Join(got, ",") != "on_update" {
+		t.Errorf("declaredHooks() = %v", got)
+	}
+
+	kf.Hooks.OnDeploy = "bin/rails db:prepare"
+	if got := declaredHooks(kf); strings.Join(got, ",") != "on_deploy,on_update" {
+		t.Errorf("declaredHooks() = %v, want on_deploy first", got)
+	}
+
+	if _, ok := hookCommand(kf, "on_destroy"); ok {
+		t.Error("on_destroy should not be a known hook")
+	}
+}
+
+func TestReportHooks(t *testing.T) {
+	results := []hookResult{
+		{Hook: "on_deploy", Command: "bin/rails db:prepare", DurationMS: 4200},
+		{Hook: "on_update", Command: "bin/rails db:migrate", ExitCode: 1, DurationMS: 900},
+	}
+	err := reportHooks(results)
+	if err == nil || err.Error() != "on_update hook failed with exit status 1" {
+		t.Errorf("expected on_update failure, got %v", err)
+	}
+
+	if err := reportHooks(results[:1]); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+}
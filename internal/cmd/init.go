@@ -23,40 +23,46 @@ func init() {
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Interactive project setup wizard",
-	Args:  cobra.NoArgs,
+	Long: `Detect the project's stack, processes and deps and write kyper.yml and
+.kyperignore, plus a Dockerfile when there isn't one.
+
+With --yes or --answers, init runs without prompts: detected values are used
+unless answers.yml or the per-field flags (which win) say otherwise.`,
+	Example: `  kyper init
+  kyper init --yes --category developer_tools --description "Issue tracker" --tier standard
+  kyper init --answers answers.yml --json`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if jsonOutput {
-			return fmt.Errorf("init command requires interactive mode (remove --json flag)")
+		if jsonOutput && !nonInteractiveInit() {
+			return fmt.Errorf("init --json requires --yes or --answers")
+		}
+		answers, err := loadInitAnswers(cmd)
+		if err != nil {
+			return err
 		}
 
-		cwd, err := os.Getwd()
+		// Detection runs in the directory kyper.yml will live in, so
+		// `kyper init -f apps/web/kyper.yml` scaffolds a monorepo app.
+		kyperPath := kyperFilePath()
+		cwd, err := filepath.Abs(filepath.Dir(kyperPath))
 		if err != nil {
 			return err
 		}
 		defaultTitle := toHumanTitle(filepath.Base(cwd))
 
 		// Step 2: Auto-detect (before step 1 form so detection runs first)
-		stacks := detect.DetectStack(cwd)
-		detectedProcesses := detect.DetectProcesses(cwd)
-		detectedDeps := detect.DetectDeps(cwd)
-
-		if len(stacks) > 0 || len(detectedProcesses) > 0 || len(detectedDeps) > 0 {
-			fmt.Println()
-			fmt.Println(ui.Bold.Render("Auto-detected:"))
-			for _, s := range stacks {
-				fmt.Printf("  Stack: %s (%s)\n", ui.InfoStyle.Render(s.Name), ui.DimStyle.Render(s.Source))
-			}
-			for _, p := range detectedProcesses {
-				fmt.Printf("  Process: %s → %s (%s)\n", ui.InfoStyle.Render(p.Name), p.Command, ui.DimStyle.Render(p.Source))
-			}
-			for _, d := range detectedDeps {
-				fmt.Printf("  Dep: %s (%s)\n", ui.InfoStyle.Render(d.Name), ui.DimStyle.Render(d.Source))
-			}
-			fmt.Println()
+		detection := detectProject(cwd)
+		if nonInteractiveInit() {
+			return runInitNonInteractive(kyperPath, cwd, detection, answers)
 		}
+		stacks := detection.Stacks
+		detectedProcesses := detection.Processes
+		detectedDeps := detection.Deps
+		printDetection(detection)
 
-		// Step 1: App basics — split into two groups so the category list doesn't crowd other fields
-		var title, category, tagline, description string
+		// Step 1: App basics — split into two groups so the category list doesn't crowd other fields.
+		// Flags and --answers prefill the prompts.
+		title, category, tagline, description := answers.Title, answers.Category, answers.Tagline, answers.Description
 
 		err = huh.NewForm(
 			huh.NewGroup(
@@ -108,6 +114,9 @@ var initCmd = &cobra.Command{
 			}
 		}
 
+		if answers.Web != "" {
+			processes["web"] = answers.Web
+		}
 		if _, ok := processes["web"]; !ok {
 			var webCmd string
 			if err := huh.NewInput().
@@ -127,7 +136,7 @@ var initCmd = &cobra.Command{
 			for i, d := range detectedDeps {
 				depOptions[i] = huh.NewOption(fmt.Sprintf("%s (from %s)", d.Name, d.Source), d.Name)
 			}
-			var chosen []string
+			chosen := answers.Deps
 			if err := huh.NewMultiSelect[string]().
 				Title("Select dependencies").
 				Options(depOptions...).
@@ -154,7 +163,7 @@ var initCmd = &cobra.Command{
 
 		// Step 5: Hooks
 		stackNames := detect.StackNames(stacks)
-		var onDeploy string
+		onDeploy := answers.OnDeploy
 		hasDB := false
 		for _, d := range selectedDeps {
 			if d.Name == "postgres" || d.Name == "mysql" {
@@ -163,7 +172,7 @@ var initCmd = &cobra.Command{
 			}
 		}
 		if hasDB {
-			suggestion := suggestHook(stackNames)
+			suggestion := suggestHook(stacks)
 			if err := huh.NewInput().
 				Title("Deploy hook").
 				Description("Run after first deployment (e.g., database migration)").
@@ -179,7 +188,13 @@ var initCmd = &cobra.Command{
 
 		// Steps 6–8: Health check, pricing, resources — one form to avoid terminal artifacts
 		defaultPath := defaultHealthPath(stackNames)
-		var healthPath, oneTimeStr, subStr, memoryTier string
+		healthPath, oneTimeStr, subStr := answers.HealthPath, answers.PriceOneTime, answers.PriceSubscription
+		var memoryTier string
+		if answers.Tier != "" {
+			if memoryTier, err = tierMemory(answers.Tier); err != nil {
+				return err
+			}
+		}
 		err = huh.NewForm(
 			huh.NewGroup(
 				huh.NewInput().
@@ -220,6 +235,7 @@ var initCmd = &cobra.Command{
 		// Build KyperFile struct
 		kf := buildKyperFile(title, description, tagline, category, processes,
 			selectedDeps, onDeploy, healthPath, oneTimeStr, subStr, memoryTier)
+		kf.Dir = cwd
 
 		// Step 9: Preview
 		yamlBytes, err := yaml.Marshal(kf)
@@ -248,7 +264,7 @@ var initCmd = &cobra.Command{
 		}
 
 		// Detect whether we'd be overwriting an existing file
-		_, statErr := os.Stat("kyper.yml")
+		_, statErr := os.Stat(kyperPath)
 		kyperYmlExists := statErr == nil
 		confirmTitle := "Write kyper.yml?"
 		if kyperYmlExists {
@@ -268,7 +284,7 @@ var initCmd = &cobra.Command{
 			return nil
 		}
 
-		if err := os.WriteFile("kyper.yml", yamlBytes, 0644); err != nil {
+		if err := os.WriteFile(kyperPath, yamlBytes, 0644); err != nil {
 			return fmt.Errorf("writing kyper.yml: %w", err)
 		}
 
@@ -279,19 +295,36 @@ var initCmd = &cobra.Command{
 		}
 
 		// Generate .kyperignore if it doesn't already exist
-		if _, err := os.Stat(".kyperignore"); os.IsNotExist(err) {
-			kyperignoreContent := buildKyperignore(stackNames)
-			if err := os.WriteFile(".kyperignore", []byte(kyperignoreContent), 0644); err != nil {
-				ui.PrintWarning(fmt.Sprintf("Could not create .kyperignore: %v", err))
-			} else {
-				ui.PrintSuccess("Created .kyperignore with sensible defaults")
-			}
+		if _, err := writeKyperignore(cwd, stackNames); err != nil {
+			return err
 		}
 
-		return nil
+		if answers.NoDockerfile {
+			return nil
+		}
+		return offerDockerfile(kf, stacks)
 	},
 }
 
+// writeKyperignore creates .kyperignore in dir unless it exists, returning
+// its path if it was created. Failing to write it is only a warning.
+func writeKyperignore(dir string, stacks []string) (string, error) {
+	path := filepath.Join(dir, ".kyperignore")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return "", nil
+	}
+	if err := os.WriteFile(path, []byte(buildKyperignore(stacks)), 0644); err != nil {
+		if !jsonOutput {
+			ui.PrintWarning(fmt.Sprintf("Could not create .kyperignore: %v", err))
+		}
+		return "", nil
+	}
+	if !jsonOutput {
+		ui.PrintSuccess("Created .kyperignore with sensible defaults")
+	}
+	return path, nil
+}
+
 func buildKyperFile(title, description, tagline, category string,
 	processes map[string]string, deps []config.DepEntry,
 	onDeploy, healthPath, oneTimeStr, subStr, memoryTier string) *config.KyperFile {
@@ -372,31 +405,24 @@ func parseInt(s string) int {
 	return n
 }
 
-func suggestHook(stacks []string) string {
+// suggestHook returns the deploy hook of the first detected stack that has
+// one. Detection leaves out hooks whose tool the project doesn't use.
+func suggestHook(stacks []detect.StackResult) string {
 	for _, s := range stacks {
-		switch s {
-		case "rails":
-			return "bundle exec rails db:migrate"
-		case "django":
-			return "python manage.py migrate"
-		case "prisma":
-			return "npx prisma migrate deploy"
-		case "laravel":
-			return "php artisan migrate --force"
+		if s.Hook != "" {
+			return s.Hook
 		}
 	}
 	return ""
 }
 
+// defaultHealthPath returns the health path of the first stack whose rule
+// has one, or /up.
 func defaultHealthPath(stacks []string) string {
+	rules, _ := detect.Default()
 	for _, s := range stacks {
-		switch s {
-		case "rails":
-			return "/up"
-		case "django":
-			return "/health/"
-		case "express", "next", "nest", "koa":
-			return "/health"
+		if r := rules.Stack(s); r != nil && r.HealthPath != "" {
+			return r.HealthPath
 		}
 	}
 	return "/up"
@@ -417,31 +443,25 @@ func buildKyperignore(stacks []string) string {
 	b.WriteString("*.key\n")
 	b.WriteString("*.p12\n")
 
+	rules, _ := detect.Default()
+	written := map[string]bool{}
 	for _, s := range stacks {
-		switch s {
-		case "rails":
-			b.WriteString("\n# Rails\n")
-			b.WriteString("log/\n")
-			b.WriteString("storage/\n")
-			b.WriteString(".bundle/\n")
-		case "django":
-			b.WriteString("\n# Django\n")
-			b.WriteString("__pycache__/\n")
-			b.WriteString("*.pyc\n")
-			b.WriteString(".venv/\n")
-			b.WriteString("venv/\n")
-			b.WriteString("staticfiles/\n")
-		case "laravel":
-			b.WriteString("\n# Laravel\n")
-			b.WriteString("vendor/\n")
-			b.WriteString("storage/\n")
-		case "next":
-			b.WriteString("\n# Next.js\n")
-			b.WriteString(".next/\n")
-			b.WriteString("out/\n")
-		case "express", "koa", "nest":
-			b.WriteString("\n# Node\n")
-			b.WriteString("dist/\n")
+		r := rules.Stack(s)
+		if r == nil || len(r.Ignore) == 0 {
+			continue
+		}
+		label := r.Label
+		if label == "" {
+			label = r.Name
+		}
+		// Stacks that share a label, like Express and Koa, share a section.
+		if written[label] {
+			continue
+		}
+		written[label] = true
+		b.WriteString("\n# " + label + "\n")
+		for _, pattern := range r.Ignore {
+			b.WriteString(pattern + "\n")
 		}
 	}
 
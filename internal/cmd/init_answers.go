@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/detect"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/scaffold"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	initYes         bool
+	initForce       bool
+	initAnswersPath string
+	initFlags       initAnswers
+)
+
+func init() {
+	f := initCmd.Flags()
+	f.BoolVarP(&initYes, "yes", "y", false, "Accept detected defaults without prompting")
+	f.BoolVar(&initForce, "force", false, "Overwrite an existing kyper.yml in non-interactive mode")
+	f.StringVar(&initAnswersPath, "answers", "", "YAML file with answers; implies --yes")
+	f.StringVar(&initFlags.Title, "title", "", "App title (default: from the directory name)")
+	f.StringVar(&initFlags.Category, "category", "", "Category: "+strings.Join(kyperfile.Categories, ", "))
+	f.StringVar(&initFlags.Tagline, "tagline", "", "Short pitch, up to 160 characters")
+	f.StringVar(&initFlags.Description, "description", "", "What the app does, up to 500 characters")
+	f.StringVar(&initFlags.Web, "web", "", "Web process command (default: detected)")
+	f.StringSliceVar(&initFlags.Deps, "deps", nil, "Deps to declare, comma-separated (default: all detected)")
+	f.StringVar(&initFlags.OnDeploy, "on-deploy", "", "Deploy hook (default: suggested migration command when there's a database)")
+	f.StringVar(&initFlags.HealthPath, "health-path", "", "Health check path (default: the stack's convention)")
+	f.StringVar(&initFlags.PriceOneTime, "price-one-time", "", "One-time price in USD")
+	f.StringVar(&initFlags.PriceSubscription, "price-subscription", "", "Monthly subscription price in USD")
+	f.StringVar(&initFlags.Tier, "tier", "", "Resource tier: starter, standard or pro (default: starter)")
+	f.BoolVar(&initFlags.NoDockerfile, "no-dockerfile", false, "Don't generate a Dockerfile when there isn't one")
+}
+
+// initAnswers holds init's answers from --answers and the per-field flags.
+type initAnswers struct {
+	Title             string            `yaml:"title"`
+	Category          string            `yaml:"category"`
+	Tagline           string            `yaml:"tagline"`
+	Description       string            `yaml:"description"`
+	Web               string            `yaml:"web"`
+	Processes         map[string]string `yaml:"processes"`
+	Deps              []string          `yaml:"deps"`
+	OnDeploy          string            `yaml:"on_deploy"`
+	HealthPath        string            `yaml:"health_path"`
+	PriceOneTime      string            `yaml:"price_one_time"`
+	PriceSubscription string            `yaml:"price_subscription"`
+	Tier              string            `yaml:"tier"`
+	NoDockerfile      bool              `yaml:"no_dockerfile"`
+}
+
+// initDetection is what init found in the project directory.
+type initDetection struct {
+	Stacks    []detect.StackResult
+	Processes []detect.ProcessResult
+	Deps      []detect.DepResult
+}
+
+func detectProject(dir string) initDetection {
+	if _, err := detect.Default(); err != nil && !jsonOutput {
+		for _, w := range ruleWarnings(err) {
+			ui.PrintWarning(w)
+		}
+	}
+	return initDetection{
+		Stacks:    detect.DetectStack(dir),
+		Processes: detect.DetectProcesses(dir),
+		Deps:      detect.DetectDeps(dir),
+	}
+}
+
+// nonInteractiveInit reports whether init should run without prompts.
+func nonInteractiveInit() bool {
+	return initYes || initAnswersPath != ""
+}
+
+// loadInitAnswers reads --answers, if given, and overlays the flags the
+// user set on the command line.
+func loadInitAnswers(cmd *cobra.Command) (initAnswers, error) {
+	var a initAnswers
+	if initAnswersPath != "" {
+		data, err := os.ReadFile(initAnswersPath)
+		if err != nil {
+			return a, fmt.Errorf("reading answers: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&a); err != nil {
+			return a, fmt.Errorf("parsing %s: %w", initAnswersPath, err)
+		}
+	}
+
+	set := func(name string, dst *string, v string) {
+		if cmd.Flags().Changed(name) {
+			*dst = v
+		}
+	}
+	set("title", &a.Title, initFlags.Title)
+	set("category", &a.Category, initFlags.Category)
+	set("tagline", &a.Tagline, initFlags.Tagline)
+	set("description", &a.Description, initFlags.Description)
+	set("web", &a.Web, initFlags.Web)
+	set("on-deploy", &a.OnDeploy, initFlags.OnDeploy)
+	set("health-path", &a.HealthPath, initFlags.HealthPath)
+	set("price-one-time", &a.PriceOneTime, initFlags.PriceOneTime)
+	set("price-subscription", &a.PriceSubscription, initFlags.PriceSubscription)
+	set("tier", &a.Tier, initFlags.Tier)
+	if cmd.Flags().Changed("deps") {
+		a.Deps = initFlags.Deps
+		if a.Deps == nil {
+			a.Deps = []string{}
+		}
+	}
+	if cmd.Flags().Changed("no-dockerfile") {
+		a.NoDockerfile = initFlags.NoDockerfile
+	}
+	return a, nil
+}
+
+// tierMemory maps a tier name to its minimum memory in MB, the value the
+// interactive tier select uses.
+func tierMemory(tier string) (string, error) {
+	switch strings.ToLower(tier) {
+	case "", "starter", "512":
+		return "512", nil
+	case "standard", "1024":
+		return "1024", nil
+	case "pro", "2048":
+		return "2048", nil
+	}
+	return "", fmt.Errorf("invalid tier %q — use starter, standard or pro", tier)
+}
+
+// answersKyperFile builds kyper.yml from answers, falling back to what was
+// detected in dir.
+func answersKyperFile(dir string, d initDetection, a initAnswers) (*config.KyperFile, error) {
+	if a.Category == "" {
+		return nil, fmt.Errorf("--category is required without prompts — use one of: %s", strings.Join(kyperfile.Categories, ", "))
+	}
+	title := a.Title
+	if title == "" {
+		title = toHumanTitle(filepath.Base(dir))
+	}
+
+	processes := map[string]string{}
+	for _, p := range d.Processes {
+		processes[p.Name] = p.Command
+	}
+	for name, command := range a.Processes {
+		processes[name] = command
+	}
+	if a.Web != "" {
+		processes["web"] = a.Web
+	}
+
+	names := a.Deps
+	if names == nil {
+		for _, dep := range d.Deps {
+			names = append(names, dep.Name)
+		}
+	}
+	versions := map[string]string{}
+	for _, vs := range detect.SuggestDepVersions(dir, d.Deps) {
+		versions[vs.Dep] = vs.Version
+	}
+	var deps []config.DepEntry
+	hasDB := false
+	for _, name := range names {
+		deps = append(deps, config.DepEntry{Name: name, Version: versions[name]})
+		if name == "postgres" || name == "mysql" {
+			hasDB = true
+		}
+	}
+
+	stackNames := detect.StackNames(d.Stacks)
+	onDeploy := a.OnDeploy
+	if onDeploy == "" && hasDB {
+		onDeploy = suggestHook(d.Stacks)
+	}
+	healthPath := a.HealthPath
+	if healthPath == "" {
+		healthPath = defaultHealthPath(stackNames)
+	}
+	memory, err := tierMemory(a.Tier)
+	if err != nil {
+		return nil, err
+	}
+
+	kf := buildKyperFile(title, a.Description, a.Tagline, a.Category, processes,
+		deps, onDeploy, healthPath, a.PriceOneTime, a.PriceSubscription, memory)
+	kf.Dir = dir
+	return kf, nil
+}
+
+// runInitNonInteractive writes kyper.yml, .kyperignore and, unless
+// disabled, a Dockerfile from answers and detected defaults.
+func runInitNonInteractive(kyperPath, dir string, d initDetection, a initAnswers) error {
+	if _, err := os.Stat(kyperPath); err == nil && !initForce {
+		return fmt.Errorf("%s already exists — pass --force to overwrite it", kyperPath)
+	}
+	if !jsonOutput {
+		printDetection(d)
+	}
+
+	kf, err := answersKyperFile(dir, d, a)
+	if err != nil {
+		return err
+	}
+	result := kyperfile.Validate(kf, false)
+	if !result.Valid {
+		if jsonOutput {
+			_ = ui.PrintJSON(map[string]interface{}{
+				"valid":    false,
+				"errors":   result.Errors,
+				"warnings": result.Warnings,
+				"detected": detectionJSON(d),
+			})
+			return fmt.Errorf("generated kyper.yml would be invalid")
+		}
+		for _, e := range result.Errors {
+			ui.PrintError(e)
+		}
+		return fmt.Errorf("generated kyper.yml would be invalid — pass the missing answers as flags or in --answers")
+	}
+	if !jsonOutput {
+		for _, w := range result.Warnings {
+			ui.PrintWarning(w)
+		}
+	}
+
+	yamlBytes, err := yaml.Marshal(kf)
+	if err != nil {
+		return fmt.Errorf("generating YAML: %w", err)
+	}
+	if err := os.WriteFile(kyperPath, yamlBytes, 0644); err != nil {
+		return fmt.Errorf("writing kyper.yml: %w", err)
+	}
+	created := []string{kyperPath}
+	if !jsonOutput {
+		ui.PrintSuccess(fmt.Sprintf("Wrote %s", kyperPath))
+	}
+
+	stackNames := detect.StackNames(d.Stacks)
+	if path, err := writeKyperignore(dir, stackNames); err != nil {
+		return err
+	} else if path != "" {
+		created = append(created, path)
+	}
+
+	template := ""
+	if _, err := os.Stat(kf.DockerfilePath()); os.IsNotExist(err) && !a.NoDockerfile {
+		if template = scaffold.TemplateFor(stackNames); template != "" {
+			paths, err := writeDockerfile(kf, dockerfileData(kf, template, d.Stacks))
+			if err != nil {
+				return err
+			}
+			created = append(created, paths...)
+		} else if !jsonOutput {
+			ui.PrintWarning(fmt.Sprintf("No Dockerfile at %s — add one before running 'kyper build'", kf.Docker.Dockerfile))
+		}
+	}
+
+	if jsonOutput {
+		for i, p := range created {
+			created[i] = relPath(p)
+		}
+		out := map[string]interface{}{
+			"valid":    true,
+			"path":     relPath(kyperPath),
+			"created":  created,
+			"warnings": result.Warnings,
+			"detected": detectionJSON(d),
+		}
+		if template != "" {
+			out["dockerfile_template"] = template
+		}
+		return ui.PrintJSON(out)
+	}
+	return nil
+}
+
+// relPath returns p relative to the working directory when it's inside it.
+func relPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return p
+}
+
+func printDetection(d initDetection) {
+	if len(d.Stacks) == 0 && len(d.Processes) == 0 && len(d.Deps) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(ui.Bold.Render("Auto-detected:"))
+	for _, s := range d.Stacks {
+		name := s.Name
+		if s.Version != "" {
+			name += " " + s.Version
+		}
+		fmt.Printf("  Stack: %s (%s)\n", ui.InfoStyle.Render(name), ui.DimStyle.Render(s.Source))
+	}
+	for _, rt := range detectedRuntimes(d.Stacks) {
+		version := rt.Version
+		if rt.Constraint != "" {
+			version = rt.Constraint
+		}
+		fmt.Printf("  Runtime: %s %s (%s)\n", ui.InfoStyle.Render(rt.Language), version, ui.DimStyle.Render(rt.Source))
+	}
+	for _, p := range d.Processes {
+		fmt.Printf("  Process: %s → %s (%s)\n", ui.InfoStyle.Render(p.Name), p.Command, ui.DimStyle.Render(p.Source))
+	}
+	for _, dep := range d.Deps {
+		fmt.Printf("  Dep: %s (%s)\n", ui.InfoStyle.Render(dep.Name), ui.DimStyle.Render(dep.Source))
+	}
+	fmt.Println()
+}
+
+// detectedRuntimes returns each pinned runtime once; stacks on the same
+// language share one.
+func detectedRuntimes(stacks []detect.StackResult) []detect.RuntimeResult {
+	var runtimes []detect.RuntimeResult
+	seen := map[string]bool{}
+	for _, s := range stacks {
+		if s.Runtime.Version == "" || seen[s.Runtime.Language] {
+			continue
+		}
+		seen[s.Runtime.Language] = true
+		runtimes = append(runtimes, s.Runtime)
+	}
+	return runtimes
+}
+
+func detectionJSON(d initDetection) map[string]interface{} {
+	stacks := []map[string]string{}
+	for _, s := range d.Stacks {
+		stack := map[string]string{"name": s.Name, "source": s.Source}
+		if s.Version != "" {
+			stack["version"] = s.Version
+			stack["version_source"] = s.VersionSource
+		}
+		stacks = append(stacks, stack)
+	}
+	runtimes := []map[string]string{}
+	for _, rt := range detectedRuntimes(d.Stacks) {
+		runtime := map[string]string{"language": rt.Language, "version": rt.Version, "source": rt.Source}
+		if rt.Constraint != "" {
+			runtime["constraint"] = rt.Constraint
+		}
+		runtimes = append(runtimes, runtime)
+	}
+	processes := []map[string]string{}
+	for _, p := range d.Processes {
+		processes = append(processes, map[string]string{"name": p.Name, "command": p.Command, "source": p.Source})
+	}
+	deps := []map[string]string{}
+	for _, dep := range d.Deps {
+		deps = append(deps, map[string]string{"name": dep.Name, "source": dep.Source})
+	}
+	return map[string]interface{}{"stacks": stacks, "runtimes": runtimes, "processes": processes, "deps": deps}
+}
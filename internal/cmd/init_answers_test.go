@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func setupRailsProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"config/application.rb": "",
+		"Gemfile":               "gem \"rails\"\ngem \"pg\"\n",
+		"Procfile":              "web: bin/rails server -p $PORT\n",
+		".ruby-version":         "3.3.5\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadInitAnswers(t *testing.T) {
+	dir := t.TempDir()
+	answersPath := filepath.Join(dir, "answers.yml")
+	if err := os.WriteFile(answersPath, []byte("title: From File\ncategory: finance\nprice_one_time: 49\ndeps: [redis]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldPath, oldFlags := initAnswersPath, initFlags
+	defer func() { initAnswersPath, initFlags = oldPath, oldFlags }()
+	initAnswersPath = answersPath
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&initFlags.Title, "title", "", "")
+	cmd.Flags().StringSliceVar(&initFlags.Deps, "deps", nil, "")
+	if err := cmd.Flags().Parse([]string{"--title", "From Flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := loadInitAnswers(cmd)
+	if err != nil {
+		t.Fatalf("loadInitAnswers failed: %v", err)
+	}
+	if a.Title != "From Flag" || a.Category != "finance" || a.PriceOneTime != "49" || strings.Join(a.Deps, ",") != "redis" {
+		t.Errorf("unexpected answers: %+v", a)
+	}
+
+	if err := os.WriteFile(answersPath, []byte("titel: typo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadInitAnswers(cmd); err == nil || !strings.Contains(err.Error(), "titel") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestAnswersKyperFile(t *testing.T) {
+	dir := setupRailsProject(t)
+	d := detectProject(dir)
+
+	if _, err := answersKyperFile(dir, d, initAnswers{}); err == nil || !strings.Contains(err.Error(), "--category is required") {
+		t.Errorf("expected missing category error, got %v", err)
+	}
+	if _, err := answersKyperFile(dir, d, initAnswers{Category: "finance", Tier: "huge"}); err == nil {
+		t.Error("expected invalid tier error")
+	}
+
+	kf, err := answersKyperFile(dir, d, initAnswers{Category: "finance", Tier: "pro", Web: "bundle exec puma"})
+	if err != nil {
+		t.Fatalf("answersKyperFile failed: %v", err)
+	}
+	if kf.Name != toHumanTitle(filepath.Base(dir)) || kf.Processes["web"] != "bundle exec puma" {
+		t.Errorf("unexpected kyper.yml: %+v", kf)
+	}
+	if len(kf.Deps) != 1 || kf.Deps[0].Name != "postgres" || kf.Hooks.OnDeploy != "bundle exec rails db:migrate" {
+		t.Errorf("detected deps and hook should be accepted: %+v %+v", kf.Deps, kf.Hooks)
+	}
+	if kf.Resources.MinMemoryMB != 2048 || kf.Healthcheck.Path != "/up" {
+		t.Errorf("unexpected tier or health path: %+v %+v", kf.Resources, kf.Healthcheck)
+	}
+
+	kf, err = answersKyperFile(dir, d, initAnswers{Category: "finance", Deps: []string{}})
+	if err != nil {
+		t.Fatalf("answersKyperFile failed: %v", err)
+	}
+	if len(kf.Deps) != 0 || kf.Hooks.OnDeploy != "" {
+		t.Errorf("--deps with no values should declare no deps: %+v", kf.Deps)
+	}
+}
+
+func TestAnswersKyperFileSkipsUnconfirmedHook(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("fastapi\npsycopg2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kf, err := answersKyperFile(dir, detectProject(dir), initAnswers{Category: "finance", Web: "uvicorn main:app"})
+	if err != nil {
+		t.Fatalf("answersKyperFile failed: %v", err)
+	}
+	if len(kf.Deps) != 1 || kf.Deps[0].Name != "postgres" {
+		t.Fatalf("expected postgres to be detected, got %+v", kf.Deps)
+	}
+	if kf.Hooks.OnDeploy != "" {
+		t.Errorf("without alembic.ini no hook should be written, got %q", kf.Hooks.OnDeploy)
+	}
+}
+
+func TestRunInitNonInteractiveJSON(t *testing.T) {
+	dir := setupRailsProject(t)
+	kyperPath := filepath.Join(dir, "kyper.yml")
+
+	oldJSON, oldForce := jsonOutput, initForce
+	defer func() { jsonOutput, initForce = oldJSON, oldForce }()
+	jsonOutput = true
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = w
+	runErr := runInitNonInteractive(kyperPath, dir, detectProject(dir), initAnswers{Category: "finance", Description: "Invoices", PriceSubscription: "12"})
+	_ = w.Close()
+	os.Stdout = origStdout
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	if runErr != nil {
+		t.Fatalf("runInitNonInteractive error: %v\noutput: %s", runErr, buf.String())
+	}
+
+	var result struct {
+		Valid              bool     `json:"valid"`
+		Created            []string `json:"created"`
+		DockerfileTemplate string   `json:"dockerfile_template"`
+		Detected           struct {
+			Stacks   []map[string]string `json:"stacks"`
+			Runtimes []map[string]string `json:"runtimes"`
+		} `json:"detected"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("parsing JSON output: %v\noutput: %q", err, buf.String())
+	}
+	if !result.Valid || len(result.Created) != 4 || result.DockerfileTemplate != "rails" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Detected.Stacks) != 1 || result.Detected.Stacks[0]["name"] != "rails" {
+		t.Errorf("unexpected detection: %+v", result.Detected)
+	}
+	if len(result.Detected.Runtimes) != 1 || result.Detected.Runtimes[0]["version"] != "3.3.5" {
+		t.Errorf("expected ruby 3.3.5 from .ruby-version, got %+v", result.Detected.Runtimes)
+	}
+	dockerfile, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if err != nil || !strings.Contains(string(dockerfile), "ARG RUBY_VERSION=3.3.5") {
+		t.Errorf("expected the Dockerfile to use the detected ruby version, got %v:\n%s", err, dockerfile)
+	}
+
+	kf, _, err := config.LoadKyperFile(kyperPath)
+	if err != nil {
+		t.Fatalf("loading written kyper.yml: %v", err)
+	}
+	if kf.Category != "finance" || kf.Processes["web"] != "bin/rails server -p $PORT" {
+		t.Errorf("unexpected kyper.yml: %+v", kf)
+	}
+
+	err = runInitNonInteractive(kyperPath, dir, detectProject(dir), initAnswers{Category: "finance", Description: "Invoices", PriceSubscription: "12"})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected existing kyper.yml to need --force, got %v", err)
+	}
+}
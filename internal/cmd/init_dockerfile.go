@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/detect"
+	"github.com/bitfootco/kyper-cli/internal/scaffold"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/charmbracelet/huh"
+)
+
+// offerDockerfile asks to generate a production Dockerfile, and a matching
+// .dockerignore, when kyper.yml points at a Dockerfile that doesn't exist.
+func offerDockerfile(kf *config.KyperFile, stacks []detect.StackResult) error {
+	if _, err := os.Stat(kf.DockerfilePath()); err == nil {
+		return nil
+	}
+	tmpl := scaffold.TemplateFor(detect.StackNames(stacks))
+	if tmpl == "" {
+		ui.PrintWarning(fmt.Sprintf("No Dockerfile at %s — add one before running 'kyper build'", kf.Docker.Dockerfile))
+		return nil
+	}
+
+	data := dockerfileData(kf, tmpl, stacks)
+	base := fmt.Sprintf("%s %s", scaffold.Language(tmpl), data.Version)
+	switch rt := stackRuntime(stacks, scaffold.Language(tmpl)); {
+	case rt.Version == "":
+		base += " (default)"
+	case rt.Constraint != "":
+		base += fmt.Sprintf(" (%s in %s)", rt.Constraint, rt.Source)
+	default:
+		base += " from " + rt.Source
+	}
+	generate := true
+	if err := huh.NewConfirm().
+		Title("No Dockerfile found. Generate one?").
+		Description(fmt.Sprintf("Multi-stage %s image on %s, running as a non-root user and listening on $PORT", tmpl, base)).
+		Value(&generate).
+		Run(); err != nil {
+		return err
+	}
+	if !generate {
+		return nil
+	}
+	_, err := writeDockerfile(kf, data)
+	return err
+}
+
+// dockerfileData fills in a template from kyper.yml and the runtime
+// version pinned by the detected stacks.
+func dockerfileData(kf *config.KyperFile, tmpl string, stacks []detect.StackResult) scaffold.Data {
+	data := scaffold.Data{
+		Template: tmpl,
+		Version:  baseImageVersion(stackRuntime(stacks, scaffold.Language(tmpl)), scaffold.DefaultVersion(tmpl)),
+		Command:  kf.Processes["web"],
+	}
+	if tmpl == "node" {
+		data.PackageManager = scaffold.NodePackageManager(kf.ContextDir())
+	}
+	for _, s := range stacks {
+		if s.Name == "prisma" {
+			data.Prisma = true
+		}
+	}
+	for _, d := range kf.Deps {
+		switch d.Name {
+		case "postgres":
+			data.Postgres = true
+		case "mysql":
+			data.MySQL = true
+		}
+	}
+	return data
+}
+
+// baseImageVersion picks the base image version for a detected runtime. An
+// exact pin is used as-is. For a range like ">=3.8" the template's default
+// wins when the range allows it, since the range's floor is often an
+// end-of-life release; otherwise the floor is the best guess.
+func baseImageVersion(rt detect.RuntimeResult, defaultVersion string) string {
+	switch {
+	case rt.Version == "":
+		return defaultVersion
+	case rt.Constraint != "" && defaultVersion != "" && detect.Satisfies(rt.Constraint, defaultVersion):
+		return defaultVersion
+	}
+	return rt.Version
+}
+
+// stackRuntime returns the first pinned runtime for language among stacks.
+func stackRuntime(stacks []detect.StackResult, language string) detect.RuntimeResult {
+	for _, s := range stacks {
+		if s.Runtime.Language == language && s.Runtime.Version != "" {
+			return s.Runtime
+		}
+	}
+	return detect.RuntimeResult{Language: language}
+}
+
+// writeDockerfile renders the Dockerfile, and a .dockerignore at the
+// context root unless one exists. It returns the paths it created.
+func writeDockerfile(kf *config.KyperFile, data scaffold.Data) ([]string, error) {
+	dockerfile, err := scaffold.Dockerfile(data)
+	if err != nil {
+		return nil, err
+	}
+	path := kf.DockerfilePath()
+	if err := os.WriteFile(path, []byte(dockerfile), 0644); err != nil {
+		return nil, fmt.Errorf("writing Dockerfile: %w", err)
+	}
+	created := []string{path}
+	if !jsonOutput {
+		ui.PrintSuccess(fmt.Sprintf("Created %s", kf.Docker.Dockerfile))
+	}
+
+	ignorePath := filepath.Join(kf.ContextDir(), ".dockerignore")
+	if _, err := os.Stat(ignorePath); err == nil {
+		return created, nil
+	}
+	dockerignore, err := scaffold.Dockerignore(data.Template)
+	if err != nil {
+		return created, err
+	}
+	if err := os.WriteFile(ignorePath, []byte(dockerignore), 0644); err != nil {
+		if !jsonOutput {
+			ui.PrintWarning(fmt.Sprintf("Could not create .dockerignore: %v", err))
+		}
+		return created, nil
+	}
+	if !jsonOutput {
+		ui.PrintSuccess("Created .dockerignore")
+	}
+	return append(created, ignorePath), nil
+}
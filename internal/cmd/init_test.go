@@ -1,25 +1,50 @@
 package cmd
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/detect"
+)
 
 func TestSuggestHook(t *testing.T) {
 	tests := []struct {
-		stacks []string
-		want   string
+		name  string
+		files map[string]string
+		want  string
 	}{
-		{[]string{"rails"}, "bundle exec rails db:migrate"},
-		{[]string{"django"}, "python manage.py migrate"},
-		{[]string{"prisma", "next"}, "npx prisma migrate deploy"},
-		{[]string{"laravel"}, "php artisan migrate --force"},
-		{[]string{"go"}, ""},
-		{nil, ""},
+		{"rails", map[string]string{"config/application.rb": "module App; end\n"}, "bundle exec rails db:migrate"},
+		{"django", map[string]string{"manage.py": "", "requirements.txt": "django==5.0\n"}, "python manage.py migrate"},
+		{"next with prisma", map[string]string{"package.json": `{"dependencies": {"next": "14.0.0"}}`, "prisma/schema.prisma": ""}, "npx prisma migrate deploy"},
+		{"fastapi with alembic", map[string]string{"requirements.txt": "fastapi\nalembic\n", "alembic.ini": "[alembic]\n"}, "alembic upgrade head"},
+		{"fastapi without alembic", map[string]string{"requirements.txt": "fastapi\n"}, ""},
+		{"flask with flask-migrate", map[string]string{"requirements.txt": "flask\nFlask-Migrate==4.0\n"}, "flask db upgrade"},
+		{"flask without flask-migrate", map[string]string{"requirements.txt": "flask\n"}, ""},
+		{"phoenix", map[string]string{"mix.exs": "{:phoenix, \"~> 1.7\"}\n"}, ""},
+		{"axum", map[string]string{"Cargo.toml": "[dependencies]\naxum = \"0.7\"\nsqlx = \"0.8\"\n"}, ""},
+		{"dotnet", map[string]string{"App.csproj": "<Project />\n"}, ""},
+		{"nothing", nil, ""},
 	}
 
 	for _, tt := range tests {
-		got := suggestHook(tt.stacks)
-		if got != tt.want {
-			t.Errorf("suggestHook(%v) = %q, want %q", tt.stacks, got, tt.want)
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := suggestHook(detect.DetectStack(dir)); got != tt.want {
+				t.Errorf("suggestHook() = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -32,6 +57,9 @@ func TestDefaultHealthPath(t *testing.T) {
 		{[]string{"django"}, "/health/"},
 		{[]string{"express"}, "/health"},
 		{[]string{"next"}, "/health"},
+		{[]string{"spring"}, "/actuator/health"},
+		{[]string{"fastapi"}, "/health"},
+		{[]string{"sveltekit"}, "/health"},
 		{nil, "/up"},
 	}
 
@@ -43,9 +71,112 @@ func TestDefaultHealthPath(t *testing.T) {
 	}
 }
 
+func TestBuildKyperignore(t *testing.T) {
+	tests := []struct {
+		stacks []string
+		want   []string
+	}{
+		{[]string{"phoenix"}, []string{"_build/", "deps/"}},
+		{[]string{"spring"}, []string{"target/", ".gradle/"}},
+		{[]string{"actix"}, []string{"target/"}},
+		{[]string{"dotnet"}, []string{"bin/", "obj/"}},
+		{[]string{"nuxt"}, []string{".nuxt/", ".output/"}},
+		{[]string{"sveltekit"}, []string{".svelte-kit/"}},
+	}
+	for _, tt := range tests {
+		got := buildKyperignore(tt.stacks)
+		for _, want := range tt.want {
+			if !strings.Contains(got, "\n"+want+"\n") {
+				t.Errorf("buildKyperignore(%v) missing %q", tt.stacks, want)
+			}
+		}
+	}
+}
+
 func TestCategoryOptions(t *testing.T) {
 	opts := categoryOptions()
 	if len(opts) != 9 {
 		t.Errorf("expected 9 category options, got %d", len(opts))
 	}
 }
+
+func TestDockerfileDataRangeConstraints(t *testing.T) {
+	tests := []struct {
+		name  string
+		tmpl  string
+		stack string
+		lang  string
+		files map[string]string
+		want  string
+	}{
+		{"node range allows the default", "node", "next", "node", map[string]string{"package.json": `{"engines": {"node": ">=18"}}`}, "20"},
+		{"python range allows the default", "django", "django", "python", map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.8\"\n"}, "3.12"},
+		{"range below the default", "django", "django", "python", map[string]string{"pyproject.toml": "[project]\nrequires-python = \">=3.9,<3.12\"\n"}, "3.9"},
+		{"caret excludes the default", "node", "next", "node", map[string]string{"package.json": `{"engines": {"node": "^18.17"}}`}, "18.17"},
+		{"exact pin", "node", "next", "node", map[string]string{".nvmrc": "18.20.4\n"}, "18.20.4"},
+		{"nothing pinned", "django", "django", "python", nil, "3.12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			kf := &config.KyperFile{Dir: dir}
+			stacks := []detect.StackResult{{Name: tt.stack, Runtime: detect.DetectRuntime(dir, tt.lang)}}
+			if got := dockerfileData(kf, tt.tmpl, stacks).Version; got != tt.want {
+				t.Errorf("base image version = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".ruby-version"), []byte("3.3.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kf := &config.KyperFile{
+		Docker:    config.DockerConfig{Dockerfile: "./Dockerfile"},
+		Processes: map[string]string{"web": "bin/rails server -p $PORT"},
+		Deps:      []config.DepEntry{{Name: "postgres"}},
+		Dir:       dir,
+	}
+
+	stacks := []detect.StackResult{{Name: "rails", Runtime: detect.DetectRuntime(dir, "ruby")}}
+	data := dockerfileData(kf, "rails", stacks)
+	if data.Version != "3.3.5" || !data.Postgres || data.Command != "bin/rails server -p $PORT" {
+		t.Fatalf("unexpected template data: %+v", data)
+	}
+	created, err := writeDockerfile(kf, data)
+	if err != nil {
+		t.Fatalf("writeDockerfile failed: %v", err)
+	}
+	if len(created) != 2 {
+		t.Errorf("expected Dockerfile and .dockerignore to be created, got %v", created)
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "ARG RUBY_VERSION=3.3.5") || !strings.Contains(string(dockerfile), "libpq-dev") {
+		t.Errorf("unexpected Dockerfile:\n%s", dockerfile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".dockerignore")); err != nil {
+		t.Errorf("expected .dockerignore: %v", err)
+	}
+
+	// An existing .dockerignore is left alone.
+	if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeDockerfile(kf, data); err != nil {
+		t.Fatalf("writeDockerfile failed: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, ".dockerignore")); string(got) != "custom\n" {
+		t.Errorf(".dockerignore was overwritten: %q", got)
+	}
+}
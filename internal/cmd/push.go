@@ -8,14 +8,22 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/bitfootco/kyper-cli/internal/api"
-	"github.com/bitfootco/kyper-cli/internal/archive"
 	"github.com/bitfootco/kyper-cli/internal/config"
 	"github.com/bitfootco/kyper-cli/internal/kyperfile"
 	"github.com/bitfootco/kyper-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pushArchiveFormat string
+	pushAll           bool
+	pushChangedSince  string
+)
+
 func init() {
+	pushCmd.Flags().StringVar(&pushArchiveFormat, "archive-format", "", "Archive format: zip, tar.gz, or tar.zst (default: zip, unless the server doesn't accept it)")
+	pushCmd.Flags().BoolVar(&pushAll, "all", false, "Push every app listed in kyper.workspace.yml")
+	pushCmd.Flags().StringVar(&pushChangedSince, "changed-since", "", "Push only workspace apps with files changed since this git ref")
 	rootCmd.AddCommand(pushCmd)
 }
 
@@ -30,6 +38,13 @@ var pushCmd = &cobra.Command{
 			return err
 		}
 
+		if pushAll || pushChangedSince != "" {
+			if cmd.Flags().Changed("file") {
+				return fmt.Errorf("--file cannot be combined with --all or --changed-since")
+			}
+			return runWorkspacePush(client, pushChangedSince, pushArchiveFormat)
+		}
+
 		// 2. Read + validate kyper.yml
 		kf, raw, err := loadKyperYML()
 		if err != nil {
@@ -54,20 +69,16 @@ var pushCmd = &cobra.Command{
 		slug := slugFromTitle(kf.Name)
 
 		// 3. Build archive
-		tmpDir := os.TempDir()
-		zipPath := filepath.Join(tmpDir, slug+"-source.zip")
-		defer func() { _ = os.Remove(zipPath) }()
-
-		err = ui.RunWithSpinner("Building archive...", jsonOutput, func() error {
-			return archive.Create(".", zipPath)
-		})
+		format, err := resolveArchiveFormat(client, pushArchiveFormat)
 		if err != nil {
-			return fmt.Errorf("building archive: %w", err)
+			return err
 		}
+		tmpDir := os.TempDir()
+		archivePath := filepath.Join(tmpDir, slug+"-source"+format.Ext())
+		defer func() { _ = os.Remove(archivePath) }()
 
-		info, _ := os.Stat(zipPath)
-		if !jsonOutput && info != nil {
-			fmt.Printf("Archive: %s\n", humanizeBytes(info.Size()))
+		if err = buildArchive(kf, archivePath, format); err != nil {
+			return err
 		}
 
 		// 4. Sync app (create or update)
@@ -80,10 +91,13 @@ var pushCmd = &cobra.Command{
 
 		// 5. Upload version
 		var vr *api.VersionResponse
-		apiYAML := slugifyYAMLName(raw, slug)
+		apiYAML, err := uploadYAML(raw, slug, kf)
+		if err != nil {
+			return err
+		}
 		err = ui.RunWithSpinner("Uploading...", jsonOutput, func() error {
 			var uploadErr error
-			vr, uploadErr = client.CreateVersion(slug, string(apiYAML), zipPath)
+			vr, uploadErr = client.CreateVersion(slug, string(apiYAML), archivePath, string(format))
 			return uploadErr
 		})
 		if err != nil {
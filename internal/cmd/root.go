@@ -10,6 +10,7 @@ import (
 var (
 	jsonOutput bool
 	hostFlag   string
+	fileFlag   string
 )
 
 var rootCmd = &cobra.Command{
@@ -23,6 +24,7 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output raw JSON (for scripting)")
 	rootCmd.PersistentFlags().StringVar(&hostFlag, "host", "", "Override API host URL")
+	rootCmd.PersistentFlags().StringVarP(&fileFlag, "file", "f", "kyper.yml", "Path to kyper.yml")
 	rootCmd.Version = fmt.Sprintf("%s (%s, %s)", version.Version, version.Commit, version.Date)
 	rootCmd.SetVersionTemplate("kyper {{.Version}}\n")
 }
@@ -30,3 +32,13 @@ func init() {
 func Execute() error {
 	return rootCmd.Execute()
 }
+
+// ExitError makes the CLI exit with Code without printing anything, for
+// commands that pass through the exit status of a remote process.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var statusAll bool
+
 func init() {
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "Show status for every app listed in kyper.workspace.yml")
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -21,6 +24,10 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
+		if statusAll {
+			return runWorkspaceStatus(client)
+		}
+
 		kf, _, err := loadKyperYML()
 		if err != nil {
 			return err
@@ -84,7 +84,7 @@ func runTag(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("updating version: %w", err)
 	}
 
-	if err := os.WriteFile("kyper.yml", updated, 0644); err != nil {
+	if err := os.WriteFile(kyperFilePath(), updated, 0644); err != nil {
 		return fmt.Errorf("writing kyper.yml: %w", err)
 	}
 
@@ -1,20 +1,30 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/bitfootco/kyper-cli/internal/api"
-	"github.com/bitfootco/kyper-cli/internal/archive"
+	"github.com/bitfootco/kyper-cli/internal/config"
 	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/smoke"
 	"github.com/bitfootco/kyper-cli/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var testEnvFile string
 
+// testName selects a named test deploy; empty means the app's default one.
+// It's a persistent flag so every `kyper test` subcommand accepts it.
+var testName string
+
+// testNameRegexp keeps names usable as DNS labels in test deploy URLs.
+var testNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
 // maxNilDeploymentPolls is how many times tailProvisionLog will retry when the
 // deployment record is still null right after build completes (~20s window).
 const maxNilDeploymentPolls = 10
@@ -22,13 +32,25 @@ const maxNilDeploymentPolls = 10
 var (
 	testStatus  bool
 	testDestroy bool
+	testWatch   bool
+	testTTL     time.Duration
+
+	testSkipSmoke   bool
+	testSmokeFormat string
+	testSmokeOutput string
 )
 
 func init() {
 	rootCmd.AddCommand(testCmd)
+	testCmd.PersistentFlags().StringVar(&testName, "name", "", "Named test deploy to create or act on (default: the app's default test deploy)")
 	testCmd.Flags().BoolVar(&testStatus, "status", false, "Show current test deploy status")
 	testCmd.Flags().BoolVar(&testDestroy, "destroy", false, "Tear down the active test deploy")
 	testCmd.Flags().StringVar(&testEnvFile, "env-file", ".env", "Path to .env file to load for the test deployment")
+	testCmd.Flags().BoolVar(&testWatch, "watch", false, "Redeploy automatically when source files change")
+	testCmd.Flags().BoolVar(&testSkipSmoke, "skip-smoke", false, "Don't run smoke checks once the deploy is live")
+	testCmd.Flags().StringVar(&testSmokeFormat, "smoke-format", "table", "Smoke check report format: table, junit or json")
+	testCmd.Flags().StringVar(&testSmokeOutput, "smoke-output", "", "Write the smoke check report to a file instead of stdout")
+	testCmd.Flags().DurationVar(&testTTL, "ttl", 0, "Lifetime of the test deploy, e.g. 30m or 4h (default: server default, usually 1h)")
 }
 
 var testCmd = &cobra.Command{
@@ -36,8 +58,15 @@ var testCmd = &cobra.Command{
 	Short: "Build and ephemerally deploy your app for testing",
 	Long: `Build and deploy your app on Kyper's real K8s infrastructure for testing.
 The deployment includes your declared deps (Postgres, Redis, etc.) and
-auto-destroys after 1 hour.`,
+auto-destroys after 1 hour unless --ttl sets a different lifetime. Use
+'kyper test extend' to keep a running deploy around longer.`,
 	Args: cobra.NoArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if testName != "" && !testNameRegexp.MatchString(testName) {
+			return fmt.Errorf("invalid --name %q — use up to 32 lowercase letters, digits and hyphens", testName)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		_, client, err := requireAuth()
 		if err != nil {
@@ -47,6 +76,21 @@ auto-destroys after 1 hour.`,
 		if testStatus && testDestroy {
 			return fmt.Errorf("--status and --destroy are mutually exclusive")
 		}
+		if testWatch && (testStatus || testDestroy) {
+			return fmt.Errorf("--watch cannot be combined with --status or --destroy")
+		}
+		if testWatch && jsonOutput {
+			return fmt.Errorf("--watch requires interactive mode (remove --json flag)")
+		}
+		if err := checkSmokeFlags(testSmokeFormat, testSmokeOutput); err != nil {
+			return err
+		}
+
+		if testEnvFromStdin && !testStatus && !testDestroy {
+			if testStdinEnv, err = readStdinEnv(os.Stdin); err != nil {
+				return err
+			}
+		}
 
 		kf, raw, err := loadKyperYML()
 		if err != nil {
@@ -57,158 +101,288 @@ auto-destroys after 1 hour.`,
 
 		// --status: show current test deploy
 		if testStatus {
-			return runTestStatus(client, slug)
+			return runTestStatus(client, slug, testName)
 		}
 
 		// --destroy: tear down active test deploy
 		if testDestroy {
-			return runTestDestroy(client, slug)
+			return runTestDestroy(client, slug, testName)
 		}
 
-		// Main flow: build + deploy
-		result := kyperfile.Validate(kf, true)
-		if !result.Valid {
-			if jsonOutput {
-				_ = ui.PrintJSON(result)
-				return fmt.Errorf("kyper.yml validation failed")
-			}
-			for _, e := range result.Errors {
-				ui.PrintError(e)
-			}
-			return fmt.Errorf("kyper.yml validation failed — run 'kyper validate' for details")
-		}
-		for _, w := range result.Warnings {
-			ui.PrintWarning(w)
+		if testWatch {
+			return runTestWatch(cmd, client, kf, raw)
 		}
 
-		// Build archive
-		tmpDir := os.TempDir()
-		zipPath := filepath.Join(tmpDir, slug+"-test-source.zip")
-		defer func() { _ = os.Remove(zipPath) }()
+		// Main flow: build + deploy
+		_, err = runTestDeploy(cmd, client, kf, raw, false)
+		return err
+	},
+}
+
+// runTestDeploy validates, archives and uploads a test deploy, then follows
+// it through the build and provision phases. With streamBuild the build log
+// is printed as it arrives instead of behind a spinner.
+func runTestDeploy(cmd *cobra.Command, client *api.Client, kf *config.KyperFile, raw []byte, streamBuild bool) (*api.TestDeployment, error) {
+	slug := slugFromTitle(kf.Name)
 
-		err = ui.RunWithSpinner("Building archive...", jsonOutput, func() error {
-			return archive.Create(".", zipPath)
-		})
+	if cmd.Flags().Changed("ttl") {
+		limits, err := testDeployLimits(client)
 		if err != nil {
-			return fmt.Errorf("building archive: %w", err)
+			return nil, err
 		}
-
-		info, _ := os.Stat(zipPath)
-		if !jsonOutput && info != nil {
-			fmt.Printf("Archive: %s\n", humanizeBytes(info.Size()))
+		if err := checkTTL(limits, testTTL); err != nil {
+			return nil, err
 		}
+	}
 
-		// Sync app (create or update)
-		if err = syncApp(client, slug, kf); err != nil {
-			return fmt.Errorf("syncing app: %w", err)
+	result := kyperfile.Validate(kf, true)
+	if !result.Valid {
+		if jsonOutput {
+			_ = ui.PrintJSON(result)
+			return nil, fmt.Errorf("kyper.yml validation failed")
 		}
-
-		// Load env vars from file (silently skip if missing)
-		envVars := parseEnvFile(testEnvFile)
-		if !jsonOutput && len(envVars) > 0 {
-			fmt.Printf("Loaded %d env var(s) from %s\n", len(envVars), testEnvFile)
+		for _, e := range result.Errors {
+			ui.PrintError(e)
 		}
+		return nil, fmt.Errorf("kyper.yml validation failed — run 'kyper validate' for details")
+	}
+	for _, w := range result.Warnings {
+		ui.PrintWarning(w)
+	}
+
+	// Load env vars (a missing env file is fine) and check them against the
+	// env schema before anything is uploaded.
+	envVars, err := loadTestEnv(cmd, kf)
+	if err != nil {
+		return nil, err
+	}
 
-		// Submit test deploy
-		apiYAML := slugifyYAMLName(raw, slug)
-		var tr *api.TestDeployResponse
-		err = ui.RunWithSpinner("Queuing test deploy...", jsonOutput, func() error {
-			var uploadErr error
-			tr, uploadErr = client.CreateTestDeploy(slug, string(apiYAML), zipPath, envVars)
-			return uploadErr
+	// Build archive
+	format, err := resolveArchiveFormat(client, "")
+	if err != nil {
+		return nil, err
+	}
+	// A unique temp file keeps concurrent named test deploys apart.
+	tmp, err := os.CreateTemp("", slug+"-*-test-source"+format.Ext())
+	if err != nil {
+		return nil, fmt.Errorf("creating archive: %w", err)
+	}
+	archivePath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(archivePath) }()
+
+	if err = buildArchive(kf, archivePath, format); err != nil {
+		return nil, err
+	}
+
+	// Sync app (create or update)
+	if err = syncApp(client, slug, kf); err != nil {
+		return nil, fmt.Errorf("syncing app: %w", err)
+	}
+
+	// Submit test deploy
+	apiYAML, err := uploadYAML(raw, slug, kf)
+	if err != nil {
+		return nil, err
+	}
+	var tr *api.TestDeployResponse
+	err = ui.RunWithSpinner("Queuing test deploy...", jsonOutput, func() error {
+		var uploadErr error
+		tr, uploadErr = client.CreateTestDeploy(slug, string(apiYAML), archivePath, string(format), api.TestDeployOptions{
+			Name:    testName,
+			EnvVars: envVars,
+			TTL:     testTTL,
 		})
-		if err != nil {
-			return fmt.Errorf("queuing test deploy: %w", err)
-		}
-		if tr == nil {
-			return fmt.Errorf("queuing test deploy: no response from server")
+		return uploadErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queuing test deploy: %w", err)
+	}
+	if tr == nil {
+		return nil, fmt.Errorf("queuing test deploy: no response from server")
+	}
+
+	if !jsonOutput {
+		ui.PrintSuccess(tr.Message)
+		for _, w := range tr.Warnings {
+			ui.PrintWarning(w)
 		}
+		fmt.Println()
+	}
 
-		if !jsonOutput {
-			ui.PrintSuccess(tr.Message)
-			for _, w := range tr.Warnings {
-				ui.PrintWarning(w)
-			}
+	// Phase 1: stream build log
+	if !jsonOutput {
+		fmt.Println(ui.Bold.Render("— Build phase —"))
+	}
+
+	var buildStatus, buildLog string
+	switch {
+	case streamBuild:
+		// tailLog prints the log and the final status as it goes.
+		buildStatus, err = tailLog(client, tr.VersionID, 0)
+	case jsonOutput:
+		buildStatus, _, err = waitForBuild(client, tr.VersionID, true)
+	default:
+		buildStatus, buildLog, err = waitForBuild(client, tr.VersionID, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !jsonOutput && !streamBuild {
+		printBuildStatus(buildStatus)
+	}
+
+	if buildStatus == "build_failed" {
+		if !jsonOutput && buildLog != "" {
 			fmt.Println()
+			fmt.Print(buildLog)
 		}
-
-		// Phase 1: stream build log
-		if !jsonOutput {
-			fmt.Println(ui.Bold.Render("— Build phase —"))
+		if jsonOutput {
+			_ = ui.PrintJSON(map[string]interface{}{
+				"version_id":   tr.VersionID,
+				"build_status": buildStatus,
+			})
 		}
+		return nil, fmt.Errorf("build failed — run 'kyper build' locally to debug")
+	}
 
-		var buildStatus, buildLog string
-		if jsonOutput {
-			buildStatus, _, err = waitForBuild(client, tr.VersionID, true)
-		} else {
-			buildStatus, buildLog, err = waitForBuild(client, tr.VersionID, false)
+	// Phase 2: poll provision log
+	if !jsonOutput {
+		fmt.Println()
+		fmt.Println(ui.Bold.Render("— Provision phase —"))
+		fmt.Println(ui.DimStyle.Render("Note: provisioning with deps (Postgres, Redis) can take 3–5 minutes."))
+		fmt.Println()
+	}
+
+	deployment, err := tailProvisionLog(client, slug, testName)
+	if err != nil {
+		return nil, err
+	}
+
+	if deployment.Status != "running" {
+		return nil, fmt.Errorf("test deployment provisioning failed (status: %s)", deployment.Status)
+	}
+
+	// Smoke checks against the live URL
+	var smokeResults []smoke.Result
+	if checks := smoke.Checks(kf); !testSkipSmoke && len(checks) > 0 {
+		_ = ui.RunWithSpinner("Running smoke checks...", jsonOutput, func() error {
+			smokeResults = smoke.Run(smoke.NewClient(), deployment.URL, checks)
+			return nil
+		})
+	}
+
+	// Success
+	expiresIn := formatExpiresIn(deployment.ExpiresAt)
+	if jsonOutput {
+		out := map[string]interface{}{
+			"url":        deployment.URL,
+			"expires_at": deployment.ExpiresAt,
+			"status":     deployment.Status,
 		}
-		if err != nil {
-			return err
+		if smokeResults != nil {
+			out["smoke"] = smokeResults
 		}
+		_ = ui.PrintJSON(out)
+	} else {
+		fmt.Println()
+		fmt.Println(ui.SuccessBanner.Render("✓ Test deploy is live!"))
+		fmt.Printf("  %s\n", deployment.URL)
+		fmt.Printf("  Auto-destroys %s. Run '%s' to tear it down early.\n", expiresIn, testCommand("--destroy"))
+	}
+
+	if smokeResults == nil {
+		return deployment, nil
+	}
+	if err := reportSmoke(smokeResults, testSmokeFormat, testSmokeOutput); err != nil {
+		return deployment, err
+	}
+	if failed := smoke.Failed(smokeResults); failed > 0 {
+		return deployment, fmt.Errorf("%d of %d smoke check(s) failed", failed, len(smokeResults))
+	}
+	return deployment, nil
+}
 
-		if !jsonOutput {
-			printBuildStatus(buildStatus)
+// checkSmokeFlags validates --smoke-format and --smoke-output together.
+func checkSmokeFlags(format, output string) error {
+	switch format {
+	case "table":
+		if output != "" {
+			return fmt.Errorf("--smoke-output needs --smoke-format junit or json")
 		}
+	case "junit", "json":
+	default:
+		return fmt.Errorf("unknown --smoke-format %q — use table, junit or json", format)
+	}
+	return nil
+}
+
+// reportSmoke prints or writes the smoke check report. With --json the
+// results are already part of the JSON output, so only a file is written.
+func reportSmoke(results []smoke.Result, format, output string) error {
+	if output == "" && jsonOutput {
+		return nil
+	}
 
-		if buildStatus == "build_failed" {
-			if !jsonOutput && buildLog != "" {
-				fmt.Println()
-				fmt.Print(buildLog)
+	if format == "table" {
+		fmt.Println()
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			result := ui.Success.Render("pass")
+			if !r.Passed {
+				result = ui.Error.Render("FAIL")
 			}
-			if jsonOutput {
-				_ = ui.PrintJSON(map[string]interface{}{
-					"version_id":   tr.VersionID,
-					"build_status": buildStatus,
-				})
+			status := ""
+			if r.Status != 0 {
+				status = fmt.Sprintf("%d", r.Status)
 			}
-			return fmt.Errorf("build failed — run 'kyper build' locally to debug")
-		}
-
-		// Phase 2: poll provision log
-		if !jsonOutput {
-			fmt.Println()
-			fmt.Println(ui.Bold.Render("— Provision phase —"))
-			fmt.Println(ui.DimStyle.Render("Note: provisioning with deps (Postgres, Redis) can take 3–5 minutes."))
-			fmt.Println()
+			rows[i] = []string{r.Name, status, fmt.Sprintf("%dms", r.DurationMS), result, r.Error}
 		}
+		ui.PrintTable([]string{"CHECK", "STATUS", "TIME", "RESULT", "DETAIL"}, rows)
+		return nil
+	}
 
-		deployment, err := tailProvisionLog(client, slug)
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
 		if err != nil {
-			return err
-		}
-
-		if deployment.Status != "running" {
-			return fmt.Errorf("test deployment provisioning failed (status: %s)", deployment.Status)
-		}
-
-		// Success
-		expiresIn := formatExpiresIn(deployment.ExpiresAt)
-		if jsonOutput {
-			_ = ui.PrintJSON(map[string]interface{}{
-				"url":        deployment.URL,
-				"expires_at": deployment.ExpiresAt,
-				"status":     deployment.Status,
-			})
-		} else {
-			fmt.Println()
-			fmt.Println(ui.SuccessBanner.Render("✓ Test deploy is live!"))
-			fmt.Printf("  %s\n", deployment.URL)
-			fmt.Printf("  Auto-destroys %s. Run 'kyper test --destroy' to tear it down early.\n", expiresIn)
+			return fmt.Errorf("writing smoke report: %w", err)
 		}
+		defer func() { _ = f.Close() }()
+		w = f
+	} else {
+		fmt.Println()
+	}
 
-		return nil
-	},
+	var err error
+	if format == "junit" {
+		err = smoke.WriteJUnit(w, "kyper smoke", results)
+	} else {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(map[string]interface{}{
+			"checks": results,
+			"failed": smoke.Failed(results),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("writing smoke report: %w", err)
+	}
+	if output != "" && !jsonOutput {
+		fmt.Printf("Smoke report written to %s\n", output)
+	}
+	return nil
 }
 
-func runTestStatus(client *api.Client, slug string) error {
-	status, err := client.GetTestDeploy(slug, 0)
+func runTestStatus(client *api.Client, slug, name string) error {
+	status, err := client.GetTestDeploy(slug, name, 0)
 	if err != nil {
 		if api.IsNotFound(err) {
 			if jsonOutput {
 				_ = ui.PrintJSON(map[string]interface{}{"active": false})
 			} else {
-				ui.PrintWarning("No active test deploy for this app.")
+				ui.PrintWarning("No active test deploy" + testNameSuffix(name) + ".")
 			}
 			return nil
 		}
@@ -236,11 +410,11 @@ func runTestStatus(client *api.Client, slug string) error {
 	return nil
 }
 
-func runTestDestroy(client *api.Client, slug string) error {
+func runTestDestroy(client *api.Client, slug, name string) error {
 	var resp *api.MessageResponse
 	err := ui.RunWithSpinner("Tearing down test deploy...", jsonOutput, func() error {
 		var destroyErr error
-		resp, destroyErr = client.DeleteTestDeploy(slug)
+		resp, destroyErr = client.DeleteTestDeploy(slug, name)
 		return destroyErr
 	})
 	if err != nil {
@@ -248,7 +422,7 @@ func runTestDestroy(client *api.Client, slug string) error {
 			if jsonOutput {
 				_ = ui.PrintJSON(map[string]interface{}{"active": false})
 			} else {
-				ui.PrintWarning("No active test deploy to destroy.")
+				ui.PrintWarning("No active test deploy" + testNameSuffix(name) + " to destroy.")
 			}
 			return nil
 		}
@@ -265,12 +439,12 @@ func runTestDestroy(client *api.Client, slug string) error {
 // tailProvisionLog polls GET /api/v1/apps/:slug/test_deploy until the deployment
 // reaches a terminal state, streaming provision_log content incrementally.
 // Returns the final deployment (always non-nil on nil error).
-func tailProvisionLog(client *api.Client, slug string) (*api.TestDeployment, error) {
+func tailProvisionLog(client *api.Client, slug, name string) (*api.TestDeployment, error) {
 	cursor := 0
 	nilRetries := maxNilDeploymentPolls
 
 	for {
-		status, err := client.GetTestDeploy(slug, cursor)
+		status, err := client.GetTestDeploy(slug, name, cursor)
 		if err != nil {
 			if api.IsNotFound(err) {
 				return nil, fmt.Errorf("test deploy not found — may have been cancelled")
@@ -303,6 +477,35 @@ func tailProvisionLog(client *api.Client, slug string) (*api.TestDeployment, err
 	}
 }
 
+// testCommand renders a `kyper test` invocation for hints, carrying --name
+// along when a named deploy is in use.
+func testCommand(args string) string {
+	cmd := "kyper test " + args
+	if testName != "" {
+		cmd += " --name " + testName
+	}
+	return cmd
+}
+
+// testNameSuffix describes a named test deploy in messages, e.g.
+// ` named "pr-123"`, and is empty for the default one.
+func testNameSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf(" named %q", name)
+}
+
+// noTestDeployError reports that the default or named test deploy doesn't
+// exist.
+func noTestDeployError(name string) error {
+	run := "kyper test"
+	if name != "" {
+		run += " --name " + name
+	}
+	return fmt.Errorf("no active test deploy%s — run '%s' first", testNameSuffix(name), run)
+}
+
 func formatExpiresIn(expiresAt string) string {
 	if expiresAt == "" {
 		return "in ~1 hour"
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/dotenv"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testEnvOverrides []string
+	testEnvFromStdin bool
+	testAllEnv       bool
+
+	// testStdinEnv holds the vars read by --env-from-stdin. Stdin can only be
+	// read once, so it's parsed up front and reused by --watch redeploys.
+	testStdinEnv map[string]string
+)
+
+func init() {
+	testCmd.Flags().StringArrayVar(&testEnvOverrides, "env", nil, "Set an env var for the test deploy (KEY=VALUE, repeatable; overrides --env-file)")
+	testCmd.Flags().BoolVar(&testEnvFromStdin, "env-from-stdin", false, "Read additional env vars in .env format from stdin")
+	testCmd.Flags().BoolVar(&testAllEnv, "all-env", false, "Send every loaded env var, not just those declared in kyper.yml's env list")
+}
+
+// readStdinEnv parses --env-from-stdin input.
+func readStdinEnv(r io.Reader) (map[string]string, error) {
+	vars, err := dotenv.Parse(r, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("reading env from stdin: %w", err)
+	}
+	return vars, nil
+}
+
+// loadTestEnv merges the env file, stdin and --env overrides (later sources
+// win), keeps the vars kyper.yml declares, fills in schema defaults and
+// checks the values against the schema. Values are never printed.
+func loadTestEnv(cmd *cobra.Command, kf *config.KyperFile) (map[string]string, error) {
+	// The default .env lives next to kyper.yml, which matters with --file.
+	envFile := testEnvFile
+	if !cmd.Flags().Changed("env-file") {
+		envFile = kf.ResolvePath(testEnvFile)
+	}
+	vars, err := dotenv.ReadFile(envFile, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("loading env file: %w", err)
+	}
+	if !jsonOutput && len(vars) > 0 {
+		fmt.Printf("Loaded %d env var(s) from %s\n", len(vars), envFile)
+	}
+
+	for k, v := range testStdinEnv {
+		vars[k] = v
+	}
+	for _, kv := range testEnvOverrides {
+		k, v, err := dotenv.ParseAssignment(kv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --env: %w", err)
+		}
+		vars[k] = v
+	}
+
+	return declaredEnv(kf, vars, testAllEnv, "--all-env")
+}
+
+// declaredEnv keeps the vars kyper.yml declares (everything if all is set),
+// fills in schema defaults and checks the values against the schema. allFlag
+// names the flag that disables filtering, for the warning.
+func declaredEnv(kf *config.KyperFile, vars map[string]string, all bool, allFlag string) (map[string]string, error) {
+	selected, dropped := selectEnv(vars, kf.EnvNames(), all)
+	if len(dropped) > 0 && !jsonOutput {
+		ui.PrintWarning(fmt.Sprintf("Not sending %d env var(s) not declared in kyper.yml: %s (use %s to send them)",
+			len(dropped), strings.Join(dropped, ", "), allFlag))
+	}
+	for _, e := range kf.Env {
+		if _, ok := selected[e.Name]; !ok && e.Default != "" {
+			selected[e.Name] = e.Default
+		}
+	}
+
+	// Check values against kyper.yml's env schema before using them.
+	if !jsonOutput {
+		for _, w := range kyperfile.CheckEnvValues(kf, selected).Warnings {
+			ui.PrintWarning(w)
+		}
+	}
+	if err := checkEnvSchema(kf, selected); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// selectEnv filters vars down to the declared names unless all is set, and
+// returns the sorted names it dropped.
+func selectEnv(vars map[string]string, declared []string, all bool) (selected map[string]string, dropped []string) {
+	isDeclared := make(map[string]bool)
+	for _, name := range declared {
+		isDeclared[name] = true
+	}
+
+	selected = make(map[string]string)
+	for k, v := range vars {
+		if all || isDeclared[k] {
+			selected[k] = v
+		} else {
+			dropped = append(dropped, k)
+		}
+	}
+	sort.Strings(dropped)
+	return selected, dropped
+}
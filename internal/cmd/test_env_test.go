@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestSelectEnv(t *testing.T) {
+	vars := map[string]string{"STRIPE_KEY": "sk", "DEBUG": "1", "EDITOR": "vim"}
+	declared := []string{"STRIPE_KEY", "SMTP_URL"}
+
+	selected, dropped := selectEnv(vars, declared, false)
+	if len(selected) != 1 || selected["STRIPE_KEY"] != "sk" {
+		t.Errorf("selected = %v, want only STRIPE_KEY", selected)
+	}
+	if strings.Join(dropped, ",") != "DEBUG,EDITOR" {
+		t.Errorf("dropped = %v, want [DEBUG EDITOR]", dropped)
+	}
+
+	selected, dropped = selectEnv(vars, declared, true)
+	if len(selected) != 3 || len(dropped) != 0 {
+		t.Errorf("--all-env should keep everything, got %v (dropped %v)", selected, dropped)
+	}
+}
+
+func TestLoadTestEnvPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	_ = os.WriteFile(envPath, []byte("export A=file\nB=\"file\"\nC=file\nUNDECLARED=x\n"), 0644)
+
+	oldStdin, oldOverrides, oldAll := testStdinEnv, testEnvOverrides, testAllEnv
+	defer func() { testStdinEnv, testEnvOverrides, testAllEnv = oldStdin, oldOverrides, oldAll }()
+
+	stdin, err := readStdinEnv(strings.NewReader("B=stdin\nC=stdin\n"))
+	if err != nil {
+		t.Fatalf("readStdinEnv failed: %v", err)
+	}
+	testStdinEnv = stdin
+	testEnvOverrides = []string{"C=flag=1"}
+	testAllEnv = false
+
+	kf := &config.KyperFile{Env: []config.EnvEntry{{Name: "A"}, {Name: "B"}, {Name: "C"}, {Name: "D", Default: "default"}}, Dir: dir}
+	got, err := loadTestEnv(testCmd, kf)
+	if err != nil {
+		t.Fatalf("loadTestEnv failed: %v", err)
+	}
+	want := map[string]string{"A": "file", "B": "stdin", "C": "flag=1", "D": "default"}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	kf.Env[0].Enum = []string{"x", "y"}
+	if _, err := loadTestEnv(testCmd, kf); err == nil || !strings.Contains(err.Error(), "A must be one of: x, y") {
+		t.Errorf("expected schema error, got %v", err)
+	}
+
+	testEnvOverrides = []string{"not-an-assignment"}
+	if _, err := loadTestEnv(testCmd, kf); err == nil || !strings.Contains(err.Error(), "invalid --env") {
+		t.Errorf("expected invalid --env error, got %v", err)
+	}
+}
+
+func TestLoadEnvFileAppliesSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	_ = os.WriteFile(path, []byte("STRIPE_KEY=sk_test_1\nEDITOR=vim\n"), 0644)
+	kf := &config.KyperFile{Env: []config.EnvEntry{
+		{Name: "STRIPE_KEY", Pattern: "^sk_"},
+		{Name: "LOG_LEVEL", Default: "info"},
+	}}
+
+	got, err := loadEnvFile(kf, path, false)
+	if err != nil {
+		t.Fatalf("loadEnvFile failed: %v", err)
+	}
+	if len(got) != 2 || got["STRIPE_KEY"] != "sk_test_1" || got["LOG_LEVEL"] != "info" {
+		t.Errorf("got %v", got)
+	}
+
+	got, _ = loadEnvFile(kf, path, true)
+	if got["EDITOR"] != "vim" {
+		t.Errorf("all=true should keep undeclared vars, got %v", got)
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	testExecProcess string
+	testExecTTY     bool
+)
+
+func init() {
+	testCmd.AddCommand(testExecCmd)
+	testExecCmd.Flags().StringVar(&testExecProcess, "process", "", "Run with this process's image and environment (default: web)")
+	testExecCmd.Flags().BoolVarP(&testExecTTY, "tty", "t", false, "Attach an interactive terminal")
+	// Everything after the command name belongs to the command.
+	testExecCmd.Flags().SetInterspersed(false)
+}
+
+var testExecCmd = &cobra.Command{
+	Use:   "exec [flags] -- <command> [args...]",
+	Short: "Run a one-off command inside the active test deploy",
+	Long: `Run a one-off command in a new container of the active test deploy, with
+the same image, env vars and deps (Postgres, Redis, ...) as its processes.
+Output streams back and the command's exit code becomes kyper's exit code.
+
+Use --tty for interactive programs such as a Rails console.`,
+	Example: `  kyper test exec -- bin/rails db:seed
+  kyper test exec --tty -- bin/rails console`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if testExecTTY && jsonOutput {
+			return fmt.Errorf("--tty requires interactive mode (remove --json flag)")
+		}
+
+		_, client, err := requireAuth()
+		if err != nil {
+			return err
+		}
+
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+
+		if testExecProcess != "" {
+			if _, ok := kf.Processes[testExecProcess]; !ok {
+				return fmt.Errorf("unknown process %q — kyper.yml defines: %s", testExecProcess, strings.Join(processNames(kf), ", "))
+			}
+		}
+
+		slug := slugFromTitle(kf.Name)
+		req := api.ExecRequest{Command: args, Process: testExecProcess}
+		if testExecTTY {
+			return runTestExecTTY(client, slug, testName, req)
+		}
+		return runTestExec(client, slug, testName, req)
+	},
+}
+
+// startTestExec creates the exec session, translating a missing test deploy
+// into a hint.
+func startTestExec(client *api.Client, slug, name string, req api.ExecRequest) (*api.ExecSession, error) {
+	sess, err := client.CreateTestExec(slug, name, req)
+	if err != nil {
+		if api.IsNotFound(err) {
+			return nil, noTestDeployError(name)
+		}
+		return nil, fmt.Errorf("starting command: %w", err)
+	}
+	return sess, nil
+}
+
+// runTestExec runs a command without a terminal, streaming its output until
+// it exits. A non-zero exit status is returned as an *ExitError.
+func runTestExec(client *api.Client, slug, name string, req api.ExecRequest) error {
+	sess, err := startTestExec(client, slug, name, req)
+	if err != nil {
+		return err
+	}
+
+	var output strings.Builder
+	cursor := 0
+	for {
+		out, err := client.GetTestExecOutput(slug, name, sess.ID, cursor)
+		if err != nil {
+			return fmt.Errorf("fetching command output: %w", err)
+		}
+		if jsonOutput {
+			output.WriteString(out.Output)
+		} else if out.Output != "" {
+			fmt.Print(out.Output)
+		}
+		cursor = out.Cursor
+
+		if out.Complete {
+			if jsonOutput {
+				_ = ui.PrintJSON(map[string]interface{}{
+					"exit_code": out.ExitCode,
+					"output":    output.String(),
+				})
+			}
+			if out.ExitCode != 0 {
+				return &ExitError{Code: out.ExitCode}
+			}
+			return nil
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// runTestExecTTY runs a command attached to the local terminal over a
+// websocket. The terminal is in raw mode until the command exits.
+func runTestExecTTY(client *api.Client, slug, name string, req api.ExecRequest) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("--tty needs an interactive terminal")
+	}
+	req.TTY = true
+	if cols, rows, err := term.GetSize(fd); err == nil {
+		req.Cols, req.Rows = cols, rows
+	}
+
+	sess, err := startTestExec(client, slug, name, req)
+	if err != nil {
+		return err
+	}
+	conn, err := client.AttachTestExec(slug, name, sess.ID)
+	if err != nil {
+		return fmt.Errorf("attaching to command: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("switching terminal to raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer func() {
+		signal.Stop(winch)
+		close(winch)
+	}()
+	go func() {
+		for range winch {
+			if cols, rows, err := term.GetSize(fd); err == nil {
+				_ = conn.Resize(cols, rows)
+			}
+		}
+	}()
+	go func() { _, _ = io.Copy(conn, os.Stdin) }()
+
+	code, err := pumpExec(conn, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+	return nil
+}
+
+// pumpExec copies session output to out until the command exits and returns
+// its exit code.
+func pumpExec(conn *api.ExecConn, out io.Writer) (int, error) {
+	for {
+		frame, err := conn.Next()
+		if err != nil {
+			return 0, fmt.Errorf("exec session: %w", err)
+		}
+		if frame.Exited {
+			return frame.ExitCode, nil
+		}
+		if _, err := out.Write(frame.Output); err != nil {
+			return 0, err
+		}
+	}
+}
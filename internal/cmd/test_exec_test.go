@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+)
+
+func execServer(t *testing.T, exitCode int) (*api.Client, func()) {
+	t.Helper()
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/apps/my-app/test_deploy/exec":
+			var req api.ExecRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if strings.Join(req.Command, " ") != "bin/rails db:seed" {
+				t.Errorf("unexpected command: %v", req.Command)
+			}
+			w.WriteHeader(201)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 4, "status": "starting"})
+		case r.Method == "GET" && r.URL.Path == "/api/v1/apps/my-app/test_deploy/exec/4":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"output": "Seeded 3 users\n", "cursor": 15, "complete": true, "exit_code": exitCode,
+			})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return client, srv.Close
+}
+
+func TestRunTestExecSuccess(t *testing.T) {
+	client, done := execServer(t, 0)
+	defer done()
+
+	err := runTestExec(client, "my-app", "", api.ExecRequest{Command: []string{"bin/rails", "db:seed"}})
+	if err != nil {
+		t.Fatalf("runTestExec failed: %v", err)
+	}
+}
+
+func TestRunTestExecPropagatesExitCode(t *testing.T) {
+	client, done := execServer(t, 3)
+	defer done()
+
+	err := runTestExec(client, "my-app", "", api.ExecRequest{Command: []string{"bin/rails", "db:seed"}})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected ExitError with code 3, got %v", err)
+	}
+}
+
+func TestRunTestExecNoDeploy(t *testing.T) {
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}))
+	defer srv.Close()
+
+	err := runTestExec(client, "my-app", "", api.ExecRequest{Command: []string{"true"}})
+	if err == nil || !strings.Contains(err.Error(), "no active test deploy") {
+		t.Errorf("expected no active test deploy error, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var testExtendBy time.Duration
+
+func init() {
+	testCmd.AddCommand(testExtendCmd)
+	testExtendCmd.Flags().DurationVar(&testExtendBy, "by", 30*time.Minute, "How much longer to keep the test deploy, e.g. 30m or 2h")
+}
+
+var testExtendCmd = &cobra.Command{
+	Use:   "extend",
+	Short: "Push back the auto-destroy time of the active test deploy",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, client, err := requireAuth()
+		if err != nil {
+			return err
+		}
+
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+
+		return runTestExtend(client, slugFromTitle(kf.Name), testName, testExtendBy)
+	},
+}
+
+func runTestExtend(client *api.Client, slug, name string, by time.Duration) error {
+	status, err := client.GetTestDeploy(slug, name, 0)
+	if err != nil {
+		if api.IsNotFound(err) {
+			return noTestDeployError(name)
+		}
+		return fmt.Errorf("fetching test deploy status: %w", err)
+	}
+	if status.Deployment == nil {
+		return fmt.Errorf("test deploy is still building — try again once it is running")
+	}
+
+	var remaining time.Duration
+	if t, parseErr := time.Parse(time.RFC3339, status.Deployment.ExpiresAt); parseErr == nil {
+		remaining = time.Until(t)
+	}
+	limits, err := testDeployLimits(client)
+	if err != nil {
+		return err
+	}
+	if err := checkExtension(limits, remaining, by); err != nil {
+		return err
+	}
+
+	var d *api.TestDeployment
+	err = ui.RunWithSpinner("Extending test deploy...", jsonOutput, func() error {
+		var extendErr error
+		d, extendErr = client.ExtendTestDeploy(slug, name, by)
+		return extendErr
+	})
+	if err != nil {
+		return fmt.Errorf("extending test deploy: %w", err)
+	}
+
+	if jsonOutput {
+		return ui.PrintJSON(map[string]interface{}{
+			"url":        d.URL,
+			"expires_at": d.ExpiresAt,
+			"status":     d.Status,
+		})
+	}
+	ui.PrintSuccess(fmt.Sprintf("Test deploy extended by %s — auto-destroys %s", shortDuration(by), formatExpiresIn(d.ExpiresAt)))
+	return nil
+}
+
+// testDeployLimits returns the server's advertised test deploy limits, or
+// zero limits (meaning "let the server decide") when it has no capabilities
+// endpoint. Any other error is returned so requests aren't sent unchecked.
+func testDeployLimits(client *api.Client) (api.TestDeployLimits, error) {
+	caps, err := client.GetCapabilities()
+	if err != nil {
+		if capabilitiesUnsupported(err) {
+			return api.TestDeployLimits{}, nil
+		}
+		return api.TestDeployLimits{}, fmt.Errorf("fetching server capabilities: %w", err)
+	}
+	return caps.TestDeploy, nil
+}
+
+// checkTTL validates a requested --ttl against the server's limits.
+func checkTTL(limits api.TestDeployLimits, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+	if minTTL := time.Duration(limits.MinTTLSeconds) * time.Second; minTTL > 0 && ttl < minTTL {
+		return fmt.Errorf("--ttl %s is below the server minimum of %s", shortDuration(ttl), shortDuration(minTTL))
+	}
+	if maxTTL := time.Duration(limits.MaxTTLSeconds) * time.Second; maxTTL > 0 && ttl > maxTTL {
+		return fmt.Errorf("--ttl %s exceeds the server maximum of %s", shortDuration(ttl), shortDuration(maxTTL))
+	}
+	return nil
+}
+
+// checkExtension validates extending a deploy with remaining lifetime by the
+// given amount. The maximum TTL caps how far ahead the expiry can be.
+func checkExtension(limits api.TestDeployLimits, remaining, by time.Duration) error {
+	if by <= 0 {
+		return fmt.Errorf("--by must be positive")
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	if maxTTL := time.Duration(limits.MaxTTLSeconds) * time.Second; maxTTL > 0 && remaining+by > maxTTL {
+		return fmt.Errorf("extending by %s would keep the deploy for %s, more than the server maximum of %s",
+			shortDuration(by), shortDuration(remaining+by), shortDuration(maxTTL))
+	}
+	return nil
+}
+
+// shortDuration formats d to the second without trailing zero units, e.g.
+// "1h30m" instead of "1h30m0s".
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+)
+
+func TestCheckTTL(t *testing.T) {
+	limits := api.TestDeployLimits{MinTTLSeconds: 600, MaxTTLSeconds: 8 * 3600}
+
+	tests := []struct {
+		name    string
+		limits  api.TestDeployLimits
+		ttl     time.Duration
+		wantErr string
+	}{
+		{"within limits", limits, 4 * time.Hour, ""},
+		{"below minimum", limits, 5 * time.Minute, "below the server minimum of 10m"},
+		{"above maximum", limits, 12 * time.Hour, "exceeds the server maximum of 8h"},
+		{"no limits advertised", api.TestDeployLimits{}, 48 * time.Hour, ""},
+		{"not positive", limits, 0, "must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTTL(tt.limits, tt.ttl)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckExtension(t *testing.T) {
+	limits := api.TestDeployLimits{MaxTTLSeconds: 4 * 3600}
+
+	if err := checkExtension(limits, time.Hour, 2*time.Hour); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkExtension(limits, 3*time.Hour, 2*time.Hour); err == nil {
+		t.Error("expected error when extension exceeds the maximum")
+	}
+	if err := checkExtension(limits, -time.Minute, 4*time.Hour); err != nil {
+		t.Errorf("expired deploys should count as no remaining time: %v", err)
+	}
+	if err := checkExtension(limits, time.Hour, 0); err == nil {
+		t.Error("expected error for zero --by")
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Minute:                  "30m",
+		2 * time.Hour:                     "2h",
+		90 * time.Minute:                  "1h30m",
+		90*time.Second + time.Millisecond: "1m30s",
+	}
+	for d, want := range tests {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestRunTestExtend(t *testing.T) {
+	expires := time.Now().Add(20 * time.Minute).UTC().Format(time.RFC3339)
+	extended := time.Now().Add(50 * time.Minute).UTC().Format(time.RFC3339)
+	var extendCalled bool
+
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/apps/my-app/test_deploy":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"deployment": map[string]string{"status": "running", "expires_at": expires},
+			})
+		case "/api/v1/capabilities":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"test_deploy": map[string]int{"max_ttl_seconds": 3600},
+			})
+		case "/api/v1/apps/my-app/test_deploy/extend":
+			extendCalled = true
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "running", "expires_at": extended})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	if err := runTestExtend(client, "my-app", "", 30*time.Minute); err != nil {
+		t.Fatalf("runTestExtend failed: %v", err)
+	}
+	if !extendCalled {
+		t.Error("expected extend endpoint to be called")
+	}
+
+	extendCalled = false
+	if err := runTestExtend(client, "my-app", "", time.Hour); err == nil {
+		t.Error("expected error when extension exceeds the server maximum")
+	}
+	if extendCalled {
+		t.Error("extend endpoint should not be called when validation fails")
+	}
+}
+
+func TestTestDeployLimitsErrors(t *testing.T) {
+	status := http.StatusUnauthorized
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "nope"})
+	}))
+	defer srv.Close()
+
+	if _, err := testDeployLimits(client); err == nil || !strings.Contains(err.Error(), "fetching server capabilities") || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the 401 to be reported, got %v", err)
+	}
+	if err := runTestExtend(client, "my-app", "", time.Hour); err == nil {
+		t.Error("expected test extend to fail when capabilities can't be fetched")
+	}
+
+	// Servers without a capabilities endpoint leave the limits to the server.
+	status = http.StatusNotFound
+	limits, err := testDeployLimits(client)
+	if err != nil || limits != (api.TestDeployLimits{}) {
+		t.Errorf("expected zero limits on 404, got %+v (%v)", limits, err)
+	}
+}
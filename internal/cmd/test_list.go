@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	testCmd.AddCommand(testListCmd)
+}
+
+var testListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the app's active test deploys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, client, err := requireAuth()
+		if err != nil {
+			return err
+		}
+
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+
+		return runTestList(client, slugFromTitle(kf.Name))
+	},
+}
+
+func runTestList(client *api.Client, slug string) error {
+	var deploys []api.TestDeploySummary
+	err := ui.RunWithSpinner("Fetching test deploys...", jsonOutput, func() error {
+		var listErr error
+		deploys, listErr = client.ListTestDeploys(slug)
+		return listErr
+	})
+	if err != nil {
+		return fmt.Errorf("listing test deploys: %w", err)
+	}
+
+	if jsonOutput {
+		if deploys == nil {
+			deploys = []api.TestDeploySummary{}
+		}
+		return ui.PrintJSON(map[string]interface{}{"test_deploys": deploys})
+	}
+
+	if len(deploys) == 0 {
+		ui.PrintWarning("No active test deploys for this app.")
+		return nil
+	}
+
+	rows := make([][]string, len(deploys))
+	for i, d := range deploys {
+		name := d.Name
+		if name == "" {
+			name = "(default)"
+		}
+		status := d.Status
+		if status == "" {
+			status = d.BuildStatus
+		}
+		expires := ""
+		if d.ExpiresAt != "" {
+			expires = formatExpiresIn(d.ExpiresAt)
+		}
+		rows[i] = []string{name, status, d.URL, expires}
+	}
+	ui.PrintTable([]string{"NAME", "STATUS", "URL", "EXPIRES"}, rows)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunTestList(t *testing.T) {
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/test_deploys" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"test_deploys":[{"name":"","status":"running","url":"https://a.example"},{"name":"pr-123","build_status":"building"}]}`))
+	}))
+	defer srv.Close()
+
+	if err := runTestList(client, "my-app"); err != nil {
+		t.Fatalf("runTestList failed: %v", err)
+	}
+}
+
+func TestTestNameValidation(t *testing.T) {
+	valid := []string{"pr-123", "qa", "a", "feature-login-2"}
+	invalid := []string{"PR-123", "-pr", "pr-", "pr_123", "pr.123", strings.Repeat("a", 33)}
+
+	for _, name := range valid {
+		if !testNameRegexp.MatchString(name) {
+			t.Errorf("expected %q to be a valid name", name)
+		}
+	}
+	for _, name := range invalid {
+		if testNameRegexp.MatchString(name) {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestNoTestDeployError(t *testing.T) {
+	if got := noTestDeployError("").Error(); got != "no active test deploy — run 'kyper test' first" {
+		t.Errorf("unexpected default message: %q", got)
+	}
+	if got := noTestDeployError("pr-1").Error(); got != `no active test deploy named "pr-1" — run 'kyper test --name pr-1' first` {
+		t.Errorf("unexpected named message: %q", got)
+	}
+}
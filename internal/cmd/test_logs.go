@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testLogsProcess string
+	testLogsSince   string
+	testLogsFollow  bool
+)
+
+func init() {
+	testCmd.AddCommand(testLogsCmd)
+	testLogsCmd.Flags().StringVar(&testLogsProcess, "process", "", "Only show logs from this process (e.g. web, worker)")
+	testLogsCmd.Flags().StringVar(&testLogsSince, "since", "", "Show logs newer than a duration (e.g. 10m) or RFC 3339 timestamp")
+	testLogsCmd.Flags().BoolVar(&testLogsFollow, "follow", false, "Keep streaming new log lines")
+}
+
+var testLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show runtime logs from the active test deploy",
+	Long: `Show stdout and stderr from each process of the active test deploy, as
+declared under processes in kyper.yml. Use --follow to keep streaming.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, client, err := requireAuth()
+		if err != nil {
+			return err
+		}
+
+		kf, _, err := loadKyperYML()
+		if err != nil {
+			return err
+		}
+
+		if testLogsProcess != "" {
+			if _, ok := kf.Processes[testLogsProcess]; !ok {
+				return fmt.Errorf("unknown process %q — kyper.yml defines: %s", testLogsProcess, strings.Join(processNames(kf), ", "))
+			}
+		}
+
+		opts := api.RuntimeLogOptions{Process: testLogsProcess}
+		if testLogsSince != "" {
+			opts.Since, err = parseSince(testLogsSince, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		return runTestLogs(client, slugFromTitle(kf.Name), testName, opts, testLogsFollow, processWidth(kf))
+	},
+}
+
+// runTestLogs prints runtime log lines for slug's test deploy. With follow it
+// keeps polling until the deploy goes away.
+func runTestLogs(client *api.Client, slug, name string, opts api.RuntimeLogOptions, follow bool, width int) error {
+	for {
+		logs, err := client.GetTestDeployLogs(slug, name, opts)
+		if err != nil {
+			if api.IsNotFound(err) {
+				if opts.Cursor > 0 {
+					// Streamed before, so the deploy was torn down mid-follow.
+					if !jsonOutput {
+						ui.PrintInfo("Test deploy is no longer running.")
+					}
+					return nil
+				}
+				return noTestDeployError(name)
+			}
+			return fmt.Errorf("fetching runtime logs: %w", err)
+		}
+
+		if jsonOutput && !follow {
+			return ui.PrintJSON(logs)
+		}
+		// With --follow, JSON output is one compact object per line so it
+		// can be piped.
+		enc := json.NewEncoder(os.Stdout)
+		for _, line := range logs.Lines {
+			if jsonOutput {
+				if err := enc.Encode(line); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Println(formatRuntimeLogLine(line, width))
+		}
+
+		if !follow {
+			return nil
+		}
+		if logs.Cursor > opts.Cursor {
+			opts.Cursor = logs.Cursor
+		}
+		// Later pages are selected by cursor alone.
+		opts.Since = time.Time{}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// formatRuntimeLogLine renders a log line as "<timestamp> <process> | <message>",
+// padding process names to width and highlighting stderr.
+func formatRuntimeLogLine(line api.RuntimeLogLine, width int) string {
+	ts := line.Timestamp
+	if t, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+		ts = t.Local().Format("2006-01-02 15:04:05")
+	}
+	msg := strings.TrimRight(line.Message, "\n")
+	if line.Stream == "stderr" {
+		msg = ui.Error.Render(msg)
+	}
+	process := fmt.Sprintf("%-*s", width, line.Process)
+	return fmt.Sprintf("%s %s %s", ui.DimStyle.Render(ts), ui.Bold.Render(process+" |"), msg)
+}
+
+// parseSince accepts a duration relative to now ("10m", "1h30m") or an
+// absolute RFC 3339 timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since must be a positive duration, got %q", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q — use a duration like 10m or an RFC 3339 timestamp", s)
+}
+
+// processNames returns kf's process names in sorted order.
+func processNames(kf *config.KyperFile) []string {
+	names := make([]string, 0, len(kf.Processes))
+	for name := range kf.Processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// processWidth is the length of the longest process name, used to align
+// log prefixes.
+func processWidth(kf *config.KyperFile) int {
+	width := 0
+	for name := range kf.Processes {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	return width
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 6, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"10m", now.Add(-10 * time.Minute), false},
+		{"1h30m", now.Add(-90 * time.Minute), false},
+		{"2026-03-06T15:00:00Z", time.Date(2026, 3, 6, 15, 0, 0, 0, time.UTC), false},
+		{"-5m", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSince(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRuntimeLogLine(t *testing.T) {
+	line := api.RuntimeLogLine{Timestamp: "not-a-time", Process: "web", Stream: "stdout", Message: "GET / 200\n"}
+	got := formatRuntimeLogLine(line, 6)
+	if !strings.Contains(got, "not-a-time") || !strings.Contains(got, "web    |") || !strings.HasSuffix(got, "GET / 200") {
+		t.Errorf("unexpected formatted line: %q", got)
+	}
+}
+
+func TestProcessNamesAndWidth(t *testing.T) {
+	kf := &config.KyperFile{Processes: map[string]string{"web": "x", "worker": "y", "clock": "z"}}
+	if got := strings.Join(processNames(kf), ","); got != "clock,web,worker" {
+		t.Errorf("processNames() = %s", got)
+	}
+	if got := processWidth(kf); got != 6 {
+		t.Errorf("processWidth() = %d, want 6", got)
+	}
+}
+
+func TestRunTestLogsFollowStopsWhenDeployGone(t *testing.T) {
+	var calls int32
+	var cursors []string
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursors = append(cursors, r.URL.Query().Get("cursor"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"lines":  []map[string]string{{"timestamp": "2026-03-06T16:00:01Z", "process": "web", "message": "hi"}},
+				"cursor": 5,
+			})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}))
+	defer srv.Close()
+
+	if err := runTestLogs(client, "my-app", "", api.RuntimeLogOptions{}, true, 3); err != nil {
+		t.Fatalf("runTestLogs failed: %v", err)
+	}
+	if len(cursors) != 2 || cursors[0] != "" || cursors[1] != "5" {
+		t.Errorf("unexpected cursors: %v", cursors)
+	}
+}
+
+func TestRunTestLogsNoDeploy(t *testing.T) {
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}))
+	defer srv.Close()
+
+	err := runTestLogs(client, "my-app", "", api.RuntimeLogOptions{}, false, 3)
+	if err == nil || !strings.Contains(err.Error(), "no active test deploy") {
+		t.Errorf("expected no active test deploy error, got %v", err)
+	}
+}
@@ -3,11 +3,15 @@ package cmd
 import (
 	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/smoke"
 )
 
 // TestFormatExpiresIn covers all branches of the pure helper.
@@ -75,7 +79,7 @@ func TestTailProvisionLogRunning(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	d, err := tailProvisionLog(client, "my-app")
+	d, err := tailProvisionLog(client, "my-app", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -105,7 +109,7 @@ func TestTailProvisionLogFailed(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	d, err := tailProvisionLog(client, "my-app")
+	d, err := tailProvisionLog(client, "my-app", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -132,7 +136,7 @@ func TestTailProvisionLogTerminated(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	d, err := tailProvisionLog(client, "my-app")
+	d, err := tailProvisionLog(client, "my-app", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -161,7 +165,7 @@ func TestTailProvisionLogNilDeploymentRetries(t *testing.T) {
 	// responds in microseconds so the loop exits quickly.
 	//
 	// To keep tests fast we substitute a minimal client with a fast-responding server.
-	_, err := tailProvisionLog(client, "my-app")
+	_, err := tailProvisionLog(client, "my-app", "")
 	if err == nil {
 		t.Fatal("expected error when deployment never appears, got nil")
 	}
@@ -175,7 +179,7 @@ func TestTailProvisionLog404(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := tailProvisionLog(client, "my-app")
+	_, err := tailProvisionLog(client, "my-app", "")
 	if err == nil {
 		t.Fatal("expected error on 404, got nil")
 	}
@@ -210,7 +214,7 @@ func TestTailProvisionLogCursorPropagated(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := tailProvisionLog(client, "my-app")
+	_, err := tailProvisionLog(client, "my-app", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -244,3 +248,40 @@ func TestProvisioningFailedStatusNotTreatedAsSuccess(t *testing.T) {
 		t.Error("'destroying' should not be treated as success")
 	}
 }
+
+func TestCheckSmokeFlags(t *testing.T) {
+	tests := []struct {
+		format, output string
+		wantErr        bool
+	}{
+		{"table", "", false},
+		{"junit", "smoke.xml", false},
+		{"json", "", false},
+		{"table", "smoke.txt", true},
+		{"tap", "", true},
+	}
+	for _, tt := range tests {
+		if err := checkSmokeFlags(tt.format, tt.output); (err != nil) != tt.wantErr {
+			t.Errorf("checkSmokeFlags(%q, %q) error = %v, wantErr %v", tt.format, tt.output, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReportSmokeWritesJUnitFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "smoke.xml")
+	results := []smoke.Result{
+		{Name: "home", URL: "https://example.test/", Status: 200, Passed: true},
+		{Name: "GET /up", URL: "https://example.test/up", Status: 503, Error: "expected status 200, got 503"},
+	}
+
+	if err := reportSmoke(results, "junit", out); err != nil {
+		t.Fatalf("reportSmoke failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<testsuite name="kyper smoke" tests="2" failures="1"`) {
+		t.Errorf("unexpected report:\n%s", data)
+	}
+}
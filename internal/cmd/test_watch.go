@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/archive"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// watchDebounce is how long the tree must stay quiet after a change before
+// `kyper test --watch` redeploys, so editor saves and git checkouts that
+// touch many files trigger a single deploy.
+const watchDebounce = 1500 * time.Millisecond
+
+// maxTeardownPolls bounds how long --watch waits for the previous test deploy
+// to go away before submitting the next one (~2 minutes).
+const maxTeardownPolls = 60
+
+// runTestWatch deploys once, then redeploys whenever a file that would be
+// archived changes. The previous deploy is torn down before each redeploy.
+func runTestWatch(cmd *cobra.Command, client *api.Client, kf *config.KyperFile, raw []byte) error {
+	slug := slugFromTitle(kf.Name)
+
+	envFile := testEnvFile
+	if !cmd.Flags().Changed("env-file") {
+		envFile = kf.ResolvePath(testEnvFile)
+	}
+	envPath, _ := filepath.Abs(envFile)
+
+	ws, err := newWatchSet(kf, envPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ws.watcher.Close() }()
+
+	deployed := false
+	deploy := func() {
+		if deployed {
+			if err := replaceTestDeploy(client, slug, testName); err != nil {
+				ui.PrintError(err.Error())
+				return
+			}
+		}
+		if _, err := runTestDeploy(cmd, client, kf, raw, true); err != nil {
+			ui.PrintError(err.Error())
+		}
+		deployed = true
+	}
+
+	deploy()
+
+	sigCh := make(chan os.Signal, 1)
+	changed := map[string]bool{}
+	var fire <-chan time.Time
+	for {
+		// Only catch Ctrl-C while idle; during a deploy it interrupts the
+		// CLI as usual.
+		signal.Notify(sigCh, os.Interrupt)
+		fmt.Println()
+		fmt.Println(ui.DimStyle.Render("Watching for changes... (Ctrl-C to stop)"))
+
+	wait:
+		for {
+			select {
+			case <-sigCh:
+				signal.Stop(sigCh)
+				fmt.Println()
+				ui.PrintInfo(fmt.Sprintf("Stopped watching. Run '%s' to tear down the test deploy.", testCommand("--destroy")))
+				return nil
+
+			case ev, ok := <-ws.watcher.Events:
+				if !ok {
+					return nil
+				}
+				rel, relevant := ws.eventPath(ev)
+				if !relevant {
+					continue
+				}
+				if ev.Has(fsnotify.Create) && !ws.files[ev.Name] {
+					if info, statErr := os.Lstat(ev.Name); statErr == nil && info.IsDir() {
+						if err := watchTree(ws.watcher, ws.contextDir, ev.Name, ws.matcher); err != nil {
+							ui.PrintWarning(fmt.Sprintf("watching %s: %v", rel, err))
+						}
+					}
+				}
+				changed[rel] = true
+				fire = time.After(watchDebounce)
+
+			case err, ok := <-ws.watcher.Errors:
+				if !ok {
+					return nil
+				}
+				ui.PrintWarning(fmt.Sprintf("file watcher: %v", err))
+
+			case <-fire:
+				break wait
+			}
+		}
+
+		signal.Stop(sigCh)
+		fire = nil
+		fmt.Println()
+		ui.PrintInfo(fmt.Sprintf("%d file(s) changed — redeploying", len(changed)))
+		changed = map[string]bool{}
+
+		// Pick up edits to kyper.yml itself; keep the last good copy if the
+		// new one doesn't parse. build.context or the ignore files may have
+		// changed, so the watches are rebuilt either way.
+		if newKf, newRaw, loadErr := loadKyperYML(); loadErr != nil {
+			ui.PrintError(loadErr.Error())
+		} else {
+			kf, raw = newKf, newRaw
+		}
+		if next, err := newWatchSet(kf, envPath); err != nil {
+			ui.PrintWarning(err.Error())
+		} else {
+			_ = ws.watcher.Close()
+			ws = next
+		}
+		deploy()
+	}
+}
+
+// watchSet is what --watch watches for one version of kyper.yml: the build
+// context, filtered by the archive's ignore rules, plus single files whose
+// edits change the deploy without being archived.
+type watchSet struct {
+	watcher    *fsnotify.Watcher
+	contextDir string
+	matcher    *archive.Matcher
+	// files are kyper.yml, the env file and the ignore files, by absolute
+	// path. Any of them may lie outside the context, so each one's directory
+	// is watched and its events are filtered down to the file.
+	files map[string]bool
+}
+
+func newWatchSet(kf *config.KyperFile, envPath string) (*watchSet, error) {
+	contextDir, opts, err := archiveContext(kf)
+	if err != nil {
+		return nil, err
+	}
+	ws := &watchSet{
+		contextDir: contextDir,
+		matcher:    archive.NewMatcher(contextDir, opts),
+		files:      map[string]bool{},
+	}
+	ws.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("starting file watcher: %w", err)
+	}
+	if err := watchTree(ws.watcher, contextDir, contextDir, ws.matcher); err != nil {
+		_ = ws.watcher.Close()
+		return nil, fmt.Errorf("watching %s: %w", contextDir, err)
+	}
+
+	files := []string{
+		kyperFilePath(),
+		envPath,
+		filepath.Join(contextDir, ".dockerignore"),
+		filepath.Join(contextDir, ".kyperignore"),
+	}
+	for _, dir := range opts.IgnoreDirs {
+		files = append(files, filepath.Join(dir, ".kyperignore"))
+	}
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			continue
+		}
+		ws.files[abs] = true
+		// A missing directory can't hold the file yet; there's nothing to
+		// watch.
+		_ = ws.watcher.Add(filepath.Dir(abs))
+	}
+	return ws, nil
+}
+
+// eventPath returns a display path for ev and whether it should trigger a
+// redeploy: a change to one of the watched files, or to a path the archive
+// would include.
+func (ws *watchSet) eventPath(ev fsnotify.Event) (string, bool) {
+	if ws.files[ev.Name] {
+		if ev.Op == fsnotify.Chmod {
+			return "", false
+		}
+		if rel, err := filepath.Rel(ws.contextDir, ev.Name); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel, true
+		}
+		return filepath.Base(ev.Name), true
+	}
+	return watchEventPath(ws.contextDir, ws.matcher, ev)
+}
+
+// watchTree adds dir and every subdirectory the archive would include to the
+// watcher. fsnotify watches aren't recursive, so new directories are added as
+// they appear.
+func watchTree(w *fsnotify.Watcher, root, dir string, matcher *archive.Matcher) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, relErr := filepath.Rel(root, path); relErr == nil && rel != "." && matcher.Excluded(rel, true) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
+
+// watchEventPath returns the event's path relative to root and whether the
+// change could affect the archive. Chmod-only events, excluded paths and
+// paths outside root are ignored.
+func watchEventPath(root string, matcher *archive.Matcher, ev fsnotify.Event) (string, bool) {
+	if ev.Op == fsnotify.Chmod {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, ev.Name)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	isDir := false
+	if info, statErr := os.Lstat(ev.Name); statErr == nil {
+		isDir = info.IsDir()
+	}
+	if matcher.Excluded(rel, isDir) {
+		return "", false
+	}
+	return rel, true
+}
+
+// replaceTestDeploy tears down the active test deploy and waits until it is
+// gone so the next one can take its place.
+func replaceTestDeploy(client *api.Client, slug, name string) error {
+	return ui.RunWithSpinner("Tearing down previous test deploy...", false, func() error {
+		if _, err := client.DeleteTestDeploy(slug, name); err != nil {
+			if api.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("destroying previous test deploy: %w", err)
+		}
+		for i := 0; i < maxTeardownPolls; i++ {
+			status, err := client.GetTestDeploy(slug, name, 0)
+			if err != nil {
+				if api.IsNotFound(err) {
+					return nil
+				}
+				return fmt.Errorf("polling test deploy: %w", err)
+			}
+			if status.Deployment == nil || status.Deployment.Status == "terminated" {
+				return nil
+			}
+			time.Sleep(2 * time.Second)
+		}
+		return fmt.Errorf("previous test deploy is still shutting down — try again shortly")
+	})
+}
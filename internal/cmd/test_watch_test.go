@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/archive"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestWatchTreeSkipsExcludedDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"app/models", "node_modules/left-pad", ".git/objects", "tmp/cache"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".kyperignore"), []byte("tmp/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+
+	matcher := archive.NewMatcher(root, archive.Options{})
+	if err := watchTree(w, root, root, matcher); err != nil {
+		t.Fatalf("watchTree failed: %v", err)
+	}
+
+	var got []string
+	for _, p := range w.WatchList() {
+		rel, _ := filepath.Rel(root, p)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	want := []string{".", "app", "app/models"}
+	if len(got) != len(want) {
+		t.Fatalf("watched %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("watched %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestWatchEventPath(t *testing.T) {
+	root := t.TempDir()
+	matcher := archive.NewMatcher(root, archive.Options{})
+
+	tests := []struct {
+		name string
+		ev   fsnotify.Event
+		want bool
+	}{
+		{"source write", fsnotify.Event{Name: filepath.Join(root, "app.rb"), Op: fsnotify.Write}, true},
+		{"source remove", fsnotify.Event{Name: filepath.Join(root, "old.rb"), Op: fsnotify.Remove}, true},
+		{"chmod only", fsnotify.Event{Name: filepath.Join(root, "app.rb"), Op: fsnotify.Chmod}, false},
+		{"log file", fsnotify.Event{Name: filepath.Join(root, "debug.log"), Op: fsnotify.Write}, false},
+		{"excluded dir", fsnotify.Event{Name: filepath.Join(root, "tmp", "cache.db"), Op: fsnotify.Write}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := watchEventPath(root, matcher, tt.ev); got != tt.want {
+				t.Errorf("watchEventPath(%s) = %v, want %v", tt.ev, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchSetFilesOutsideContext(t *testing.T) {
+	root := t.TempDir()
+	appDir := filepath.Join(root, "app")
+	secrets := filepath.Join(root, "secrets")
+	for _, dir := range []string{appDir, secrets, filepath.Join(appDir, "cache")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kyperPath := filepath.Join(appDir, "kyper.yml")
+	if err := os.WriteFile(kyperPath, []byte("name: app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fileFlag = kyperPath
+	defer func() { fileFlag = "" }()
+	kf := &config.KyperFile{Name: "app", Dir: appDir}
+	envPath := filepath.Join(secrets, "test.env")
+
+	ws, err := newWatchSet(kf, envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ws.watcher.Close() }()
+
+	if !slices.Contains(ws.watcher.WatchList(), secrets) {
+		t.Errorf("expected the env file's directory to be watched, got %v", ws.watcher.WatchList())
+	}
+	tests := []struct {
+		name string
+		ev   fsnotify.Event
+		want bool
+	}{
+		{"env file", fsnotify.Event{Name: envPath, Op: fsnotify.Write}, true},
+		{"env file chmod", fsnotify.Event{Name: envPath, Op: fsnotify.Chmod}, false},
+		{"sibling of env file", fsnotify.Event{Name: filepath.Join(secrets, "prod.env"), Op: fsnotify.Write}, false},
+		{"kyperignore", fsnotify.Event{Name: filepath.Join(appDir, ".kyperignore"), Op: fsnotify.Create}, true},
+		{"source file", fsnotify.Event{Name: filepath.Join(appDir, "cache", "x.rb"), Op: fsnotify.Write}, true},
+	}
+	for _, tt := range tests {
+		if _, got := ws.eventPath(tt.ev); got != tt.want {
+			t.Errorf("%s: eventPath = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A new ignore rule takes effect once the watch set is rebuilt.
+	if err := os.WriteFile(filepath.Join(appDir, ".kyperignore"), []byte("cache/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	next, err := newWatchSet(kf, envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = next.watcher.Close() }()
+	if _, got := next.eventPath(fsnotify.Event{Name: filepath.Join(appDir, "cache", "x.rb"), Op: fsnotify.Write}); got {
+		t.Error("expected cache/ to be ignored after the rebuild")
+	}
+	if slices.Contains(next.watcher.WatchList(), filepath.Join(appDir, "cache")) {
+		t.Error("expected cache/ to no longer be watched after the rebuild")
+	}
+}
+
+func TestReplaceTestDeployWaitsForTeardown(t *testing.T) {
+	polls := 0
+	client, srv := testAPIClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "DELETE":
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "Destroying"})
+		case "GET":
+			polls++
+			if polls < 2 {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"deployment": map[string]string{"status": "destroying"},
+				})
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+		}
+	}))
+	defer srv.Close()
+
+	if err := replaceTestDeploy(client, "my-app", ""); err != nil {
+		t.Fatalf("replaceTestDeploy failed: %v", err)
+	}
+	if polls != 2 {
+		t.Errorf("expected 2 status polls, got %d", polls)
+	}
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bitfootco/kyper-cli/internal/api"
+	"github.com/bitfootco/kyper-cli/internal/archive"
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"github.com/bitfootco/kyper-cli/internal/ui"
+)
+
+// workspacePushResult is the per-app outcome of `kyper push --all`.
+type workspacePushResult struct {
+	App           string `json:"app"`
+	Slug          string `json:"slug,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Status        string `json:"status"`
+	SubmissionURL string `json:"submission_url,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+func (r workspacePushResult) failed() bool {
+	return r.Error != "" || r.Status == "build_failed" || r.Status == "cancelled"
+}
+
+// workspaceStatusResult is the per-app outcome of `kyper status --all`.
+type workspaceStatusResult struct {
+	App           string           `json:"app"`
+	Slug          string           `json:"slug,omitempty"`
+	Status        string           `json:"status,omitempty"`
+	LatestVersion *api.VersionInfo `json:"latest_version,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// loadWorkspace reads kyper.workspace.yml from the current directory.
+func loadWorkspace() (*config.Workspace, error) {
+	ws, err := config.LoadWorkspace(config.WorkspaceFileName)
+	if err != nil {
+		return nil, fmt.Errorf("reading workspace: %w", err)
+	}
+	return ws, nil
+}
+
+// forEachApp runs fn for each app with at most limit calls in flight and
+// returns the results in the same order as apps.
+func forEachApp[T any](apps []string, limit int, fn func(app string) T) []T {
+	if limit < 1 {
+		limit = 1
+	}
+	results := make([]T, len(apps))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, app := range apps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, app string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = fn(app)
+		}(i, app)
+	}
+	wg.Wait()
+	return results
+}
+
+// runWorkspacePush pushes every app in the workspace, or only those changed
+// since changedSince when it is set, and prints a summary.
+func runWorkspacePush(client *api.Client, changedSince, formatOverride string) error {
+	ws, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+
+	selected := ws.Apps
+	if changedSince != "" {
+		selected, err = changedApps(ws, changedSince)
+		if err != nil {
+			return err
+		}
+	}
+	want := make(map[string]bool, len(selected))
+	for _, app := range selected {
+		want[app] = true
+	}
+
+	format, err := resolveArchiveFormat(client, formatOverride)
+	if err != nil {
+		return err
+	}
+
+	label := fmt.Sprintf("Pushing %d app(s)...", len(selected))
+	var results []workspacePushResult
+	_ = ui.RunWithSpinner(label, jsonOutput, func() error {
+		results = forEachApp(ws.Apps, ws.Limit(), func(app string) workspacePushResult {
+			if !want[app] {
+				return workspacePushResult{App: app, Status: "unchanged"}
+			}
+			return pushWorkspaceApp(client, ws, app, format)
+		})
+		return nil
+	})
+
+	failed := 0
+	for _, r := range results {
+		if r.failed() {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		_ = ui.PrintJSON(map[string]interface{}{
+			"apps":      results,
+			"succeeded": len(selected) - failed,
+			"failed":    failed,
+		})
+	} else {
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			detail := r.SubmissionURL
+			if r.Error != "" {
+				detail = r.Error
+			}
+			rows[i] = []string{r.App, r.Version, r.Status, detail}
+		}
+		ui.PrintTable([]string{"APP", "VERSION", "STATUS", "DETAIL"}, rows)
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d app(s) failed", failed, len(selected))
+	}
+	if !jsonOutput {
+		ui.PrintSuccess(fmt.Sprintf("Pushed %d app(s)", len(selected)))
+	}
+	return nil
+}
+
+// pushWorkspaceApp runs the push workflow for one workspace app without any
+// terminal output, so several can run at once.
+func pushWorkspaceApp(client *api.Client, ws *config.Workspace, app string, format archive.Format) workspacePushResult {
+	res := workspacePushResult{App: app}
+	fail := func(err error) workspacePushResult {
+		res.Status = "failed"
+		res.Error = err.Error()
+		return res
+	}
+
+	kf, raw, err := config.LoadKyperFile(ws.KyperFilePath(app))
+	if err != nil {
+		return fail(err)
+	}
+	res.Slug = slugFromTitle(kf.Name)
+
+	if result := kyperfile.Validate(kf, true); !result.Valid {
+		return fail(fmt.Errorf("kyper.yml validation failed: %s", strings.Join(result.Errors, "; ")))
+	}
+
+	tmp, err := os.CreateTemp("", res.Slug+"-*-source"+format.Ext())
+	if err != nil {
+		return fail(fmt.Errorf("creating archive: %w", err))
+	}
+	archivePath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(archivePath) }()
+
+	if _, err := createAppArchive(kf, archivePath, format); err != nil {
+		return fail(fmt.Errorf("building archive: %w", err))
+	}
+	if err := syncAppMetadata(client, res.Slug, kf); err != nil {
+		return fail(fmt.Errorf("syncing app: %w", err))
+	}
+
+	apiYAML, err := uploadYAML(raw, res.Slug, kf)
+	if err != nil {
+		return fail(err)
+	}
+	vr, err := client.CreateVersion(res.Slug, string(apiYAML), archivePath, string(format))
+	if err != nil {
+		return fail(fmt.Errorf("uploading version: %w", err))
+	}
+	res.Version = vr.Version
+
+	status, _, err := waitForBuild(client, vr.ID, true)
+	if err != nil {
+		return fail(err)
+	}
+	res.Status = status
+	if !res.failed() {
+		res.SubmissionURL = vr.SubmissionURL
+	}
+	return res
+}
+
+// runWorkspaceStatus prints the status of every app in the workspace.
+func runWorkspaceStatus(client *api.Client) error {
+	ws, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+
+	var results []workspaceStatusResult
+	_ = ui.RunWithSpinner("Fetching status...", jsonOutput, func() error {
+		results = forEachApp(ws.Apps, ws.Limit(), func(app string) workspaceStatusResult {
+			res := workspaceStatusResult{App: app}
+			kf, _, err := config.LoadKyperFile(ws.KyperFilePath(app))
+			if err != nil {
+				res.Error = err.Error()
+				return res
+			}
+			res.Slug = slugFromTitle(kf.Name)
+			status, err := client.GetAppStatus(res.Slug)
+			if err != nil {
+				res.Error = err.Error()
+				return res
+			}
+			res.Status = status.Status
+			res.LatestVersion = status.LatestVersion
+			return res
+		})
+		return nil
+	})
+
+	if jsonOutput {
+		return ui.PrintJSON(map[string]interface{}{"apps": results})
+	}
+
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		version, versionStatus := "", ""
+		if r.LatestVersion != nil {
+			version = r.LatestVersion.Version
+			versionStatus = r.LatestVersion.Status
+		}
+		status := r.Status
+		if r.Error != "" {
+			status = r.Error
+		}
+		rows[i] = []string{r.App, r.Slug, status, version, versionStatus}
+	}
+	ui.PrintTable([]string{"APP", "SLUG", "STATUS", "VERSION", "VERSION STATUS"}, rows)
+	return nil
+}
+
+// changedApps returns the workspace apps with files changed since ref,
+// counting committed, uncommitted and untracked changes.
+func changedApps(ws *config.Workspace, ref string) ([]string, error) {
+	root, err := gitOutput(ws.Dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("finding git repository: %w", err)
+	}
+	root = strings.TrimSpace(root)
+
+	diff, err := gitOutput(root, "diff", "--name-only", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("listing changes since %s: %w", ref, err)
+	}
+	untracked, err := gitOutput(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("listing untracked files: %w", err)
+	}
+
+	var changed []string
+	for _, line := range strings.Split(diff+"\n"+untracked, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changed = append(changed, line)
+		}
+	}
+	return filterChangedApps(ws, root, changed), nil
+}
+
+// filterChangedApps returns the apps whose inputs contain any of the
+// changed paths, which are slash-separated and relative to repoRoot.
+func filterChangedApps(ws *config.Workspace, repoRoot string, changed []string) []string {
+	var apps []string
+	for _, app := range ws.Apps {
+		var prefixes []string
+		for _, input := range appInputs(ws, app) {
+			dir, err := filepath.Abs(input)
+			if err != nil {
+				continue
+			}
+			if real, evalErr := filepath.EvalSymlinks(dir); evalErr == nil {
+				dir = real
+			}
+			rel, err := filepath.Rel(repoRoot, dir)
+			if err != nil {
+				continue
+			}
+			prefixes = append(prefixes, filepath.ToSlash(rel))
+		}
+		if appChanged(prefixes, changed) {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+// appInputs returns the paths that feed app's image: its directory (which
+// holds kyper.yml), the build context and the Dockerfile. The context may be
+// a shared parent such as packages/, so changes there count too.
+func appInputs(ws *config.Workspace, app string) []string {
+	inputs := []string{ws.AppDir(app)}
+	kf, _, err := config.LoadKyperFile(ws.KyperFilePath(app))
+	if err != nil {
+		return inputs
+	}
+	return append(inputs, kf.ContextDir(), kf.DockerfilePath())
+}
+
+func appChanged(prefixes, changed []string) bool {
+	for _, prefix := range prefixes {
+		for _, path := range changed {
+			if prefix == "." || path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestForEachAppBoundedAndOrdered(t *testing.T) {
+	apps := []string{"a", "b", "c", "d", "e", "f"}
+	var inFlight, maxInFlight int32
+
+	results := forEachApp(apps, 2, func(app string) string {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return app + "!"
+	})
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", maxInFlight)
+	}
+	want := []string{"a!", "b!", "c!", "d!", "e!", "f!"}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+func TestFilterChangedApps(t *testing.T) {
+	root := t.TempDir()
+	ws := &config.Workspace{Dir: root, Apps: []string{"apps/web", "apps/admin", "apps/web-api"}}
+	for _, app := range ws.Apps {
+		if err := os.MkdirAll(filepath.Join(root, app), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := []string{"apps/web/app.rb", "README.md", "packages/ui/index.js"}
+	got := filterChangedApps(ws, realRoot, changed)
+	if !reflect.DeepEqual(got, []string{"apps/web"}) {
+		t.Errorf("filterChangedApps() = %v, want [apps/web]", got)
+	}
+}
+
+func TestFilterChangedAppsSharedContext(t *testing.T) {
+	root := t.TempDir()
+	ws := &config.Workspace{Dir: root, Apps: []string{"apps/web", "apps/admin", "apps/docs"}}
+	files := map[string]string{
+		// web builds from the shared packages/ directory.
+		"apps/web/kyper.yml":   "name: web\nbuild:\n  context: ../../packages\ndocker:\n  dockerfile: ../../docker/web.Dockerfile\n",
+		"apps/admin/kyper.yml": "name: admin\n",
+		"apps/docs/README.md":  "no kyper.yml yet\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		changed []string
+		want    []string
+	}{
+		{[]string{"packages/ui/index.js"}, []string{"apps/web"}},
+		{[]string{"docker/web.Dockerfile"}, []string{"apps/web"}},
+		{[]string{"apps/admin/kyper.yml"}, []string{"apps/admin"}},
+		{[]string{"apps/docs/README.md"}, []string{"apps/docs"}},
+		{[]string{"docker/other.Dockerfile", "README.md"}, nil},
+	}
+	for _, tt := range tests {
+		if got := filterChangedApps(ws, realRoot, tt.changed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterChangedApps(%v) = %v, want %v", tt.changed, got, tt.want)
+		}
+	}
+}
+
+func TestChangedAppsFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("apps/web/app.rb", "v1")
+	write("apps/admin/app.rb", "v1")
+	write("apps/jobs/app.rb", "v1")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	write("apps/web/app.rb", "v2")         // uncommitted change
+	write("apps/jobs/new.rb", "brand new") // untracked file
+
+	ws := &config.Workspace{Dir: root, Apps: []string{"apps/web", "apps/admin", "apps/jobs"}}
+	got, err := changedApps(ws, "HEAD")
+	if err != nil {
+		t.Fatalf("changedApps failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"apps/web", "apps/jobs"}) {
+		t.Errorf("changedApps() = %v, want [apps/web apps/jobs]", got)
+	}
+
+	if _, err := changedApps(ws, "no-such-ref"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
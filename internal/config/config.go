@@ -10,6 +10,9 @@ import (
 
 type Config struct {
 	APIToken string `yaml:"api_token"`
+	// Builder is the container engine for local builds and runs: docker,
+	// podman or nerdctl. Empty means auto-detect.
+	Builder string `yaml:"builder,omitempty"`
 }
 
 func configDir() (string, error) {
@@ -20,6 +23,15 @@ func configDir() (string, error) {
 	return filepath.Join(home, ".kyper"), nil
 }
 
+// RulesDir returns ~/.kyper/detect.d, where user detection rules live.
+func RulesDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "detect.d"), nil
+}
+
 func configPath() (string, error) {
 	dir, err := configDir()
 	if err != nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,9 +20,15 @@ type KyperFile struct {
 	Deps        []DepEntry        `yaml:"deps,omitempty"`
 	Pricing     PricingConfig     `yaml:"pricing,omitempty"`
 	Resources   ResourceConfig    `yaml:"resources,omitempty"`
-	Env         []string          `yaml:"env,omitempty"`
+	Env         []EnvEntry        `yaml:"env,omitempty"`
 	Hooks       HooksConfig       `yaml:"hooks,omitempty"`
 	Healthcheck HealthcheckConfig `yaml:"healthcheck,omitempty"`
+	Build       BuildConfig       `yaml:"build,omitempty"`
+	Smoke       []SmokeCheck      `yaml:"smoke,omitempty"`
+
+	// Dir is the directory containing the loaded kyper.yml. Relative paths in
+	// the file (docker.dockerfile, build.context) resolve against it.
+	Dir string `yaml:"-"`
 }
 
 type DockerConfig struct {
@@ -29,6 +36,35 @@ type DockerConfig struct {
 	Image      string `yaml:"image,omitempty"`
 }
 
+// BuildConfig controls how the image is built. Context is the directory
+// archived and sent to the builder, relative to kyper.yml; it defaults to
+// the directory containing kyper.yml. The remaining fields are defaults for
+// local builds that the matching kyper build flags override.
+type BuildConfig struct {
+	Context  string            `yaml:"context,omitempty"`
+	Platform string            `yaml:"platform,omitempty"`
+	Target   string            `yaml:"target,omitempty"`
+	Args     map[string]string `yaml:"args,omitempty"`
+	// Secrets are BuildKit secret specs, e.g. "id=npmrc,src=.npmrc" or
+	// "id=token,env=GITHUB_TOKEN". src paths are relative to kyper.yml.
+	Secrets []string `yaml:"secrets,omitempty"`
+	// CacheFrom and CacheTo are buildx cache specs, e.g.
+	// "type=local,src=.cache"; a bare image ref means a registry cache.
+	CacheFrom []string `yaml:"cache_from,omitempty"`
+	CacheTo   []string `yaml:"cache_to,omitempty"`
+}
+
+// SecretID returns the id of a build secret spec, or an error if it has none.
+func SecretID(spec string) (string, error) {
+	for _, field := range strings.Split(spec, ",") {
+		k, v, _ := strings.Cut(field, "=")
+		if k == "id" && v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("secret %q has no id — use id=NAME,src=PATH or id=NAME,env=VAR", spec)
+}
+
 type PricingConfig struct {
 	OneTime      *float64 `yaml:"one_time,omitempty"`
 	Subscription *float64 `yaml:"subscription,omitempty"`
@@ -50,6 +86,17 @@ type HealthcheckConfig struct {
 	Timeout  int    `yaml:"timeout,omitempty"`
 }
 
+// SmokeCheck is an HTTP check run against a live test deploy. Status
+// defaults to 200, Body is a regular expression the response body must
+// match, and Timeout is in seconds like healthcheck.timeout.
+type SmokeCheck struct {
+	Name    string `yaml:"name,omitempty"`
+	Path    string `yaml:"path"`
+	Status  int    `yaml:"status,omitempty"`
+	Body    string `yaml:"body,omitempty"`
+	Timeout int    `yaml:"timeout,omitempty"`
+}
+
 // DepEntry represents a dependency with optional version and storage config.
 // Supports three YAML formats:
 //   - string: "postgres"
@@ -118,6 +165,58 @@ func (d DepEntry) MarshalYAML() (interface{}, error) {
 	return d.Name, nil
 }
 
+// EnvEntry declares an env var the app needs. Supports two YAML formats:
+//   - string: "STRIPE_KEY"
+//   - hash: {name: STRIPE_KEY, description: ..., secret: true, pattern: "^sk_"}
+//
+// Pattern is a regular expression the value must match; Enum lists the
+// allowed values. An entry is required unless it says required: false.
+type EnvEntry struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Required    *bool    `yaml:"required,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+	Secret      bool     `yaml:"secret,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+	Enum        []string `yaml:"enum,omitempty"`
+}
+
+// IsRequired reports whether the var must be set; entries are required by
+// default.
+func (e EnvEntry) IsRequired() bool {
+	return e.Required == nil || *e.Required
+}
+
+func (e *EnvEntry) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		e.Name = value.Value
+		return nil
+	}
+	if value.Kind == yaml.MappingNode {
+		// Decode into an alias type so this method isn't called recursively.
+		type plain EnvEntry
+		return value.Decode((*plain)(e))
+	}
+	return fmt.Errorf("invalid env entry format")
+}
+
+func (e EnvEntry) MarshalYAML() (interface{}, error) {
+	if e.Description == "" && e.Required == nil && e.Default == "" && !e.Secret && e.Pattern == "" && len(e.Enum) == 0 {
+		return e.Name, nil
+	}
+	type plain EnvEntry
+	return plain(e), nil
+}
+
+// EnvNames returns the names of the declared env vars in file order.
+func (kf *KyperFile) EnvNames() []string {
+	names := make([]string, len(kf.Env))
+	for i, e := range kf.Env {
+		names[i] = e.Name
+	}
+	return names
+}
+
 // LoadKyperFile reads and parses a kyper.yml file.
 // Returns the parsed struct and the raw bytes.
 func LoadKyperFile(path string) (*KyperFile, []byte, error) {
@@ -129,5 +228,47 @@ func LoadKyperFile(path string) (*KyperFile, []byte, error) {
 	if err := yaml.Unmarshal(data, &kf); err != nil {
 		return nil, nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	kf.Dir = filepath.Dir(path)
 	return &kf, data, nil
 }
+
+// ResolvePath resolves a path from kyper.yml against the file's directory.
+func (kf *KyperFile) ResolvePath(p string) string {
+	if kf.Dir == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(kf.Dir, p)
+}
+
+// DockerfilePath returns docker.dockerfile resolved against kyper.yml's directory.
+func (kf *KyperFile) DockerfilePath() string {
+	return kf.ResolvePath(kf.Docker.Dockerfile)
+}
+
+// ContextDir returns the build context directory: build.context resolved
+// against kyper.yml's directory, or that directory itself when unset.
+func (kf *KyperFile) ContextDir() string {
+	if kf.Build.Context == "" {
+		return kf.ResolvePath(".")
+	}
+	return kf.ResolvePath(kf.Build.Context)
+}
+
+// DockerfileInContext returns the Dockerfile path relative to the build
+// context, in slash form — the path the server sees inside the archive.
+// It errors if the Dockerfile lies outside the context.
+func (kf *KyperFile) DockerfileInContext() (string, error) {
+	ctx, err := filepath.Abs(kf.ContextDir())
+	if err != nil {
+		return "", err
+	}
+	dockerfile, err := filepath.Abs(kf.DockerfilePath())
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(ctx, dockerfile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("docker.dockerfile %q is outside the build context %q", kf.Docker.Dockerfile, kf.ContextDir())
+	}
+	return filepath.ToSlash(rel), nil
+}
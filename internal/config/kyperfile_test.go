@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadKyperFileValid(t *testing.T) {
@@ -158,6 +161,56 @@ deps:
 	}
 }
 
+func TestSecretID(t *testing.T) {
+	if id, err := SecretID("id=npmrc,src=.npmrc"); err != nil || id != "npmrc" {
+		t.Errorf("SecretID = %q, %v", id, err)
+	}
+	if _, err := SecretID("src=.npmrc"); err == nil {
+		t.Error("expected error for a secret without id")
+	}
+}
+
+func TestEnvEntryFormats(t *testing.T) {
+	content := `env:
+  - SMTP_URL
+  - name: STRIPE_KEY
+    description: Stripe secret key
+    secret: true
+    pattern: "^sk_"
+  - name: LOG_LEVEL
+    required: false
+    default: info
+    enum: [debug, info, warn]
+`
+	var kf KyperFile
+	if err := yaml.Unmarshal([]byte(content), &kf); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(kf.Env) != 3 {
+		t.Fatalf("expected 3 env entries, got %d", len(kf.Env))
+	}
+	if e := kf.Env[0]; e.Name != "SMTP_URL" || !e.IsRequired() {
+		t.Errorf("plain string entry = %+v", e)
+	}
+	if e := kf.Env[1]; e.Name != "STRIPE_KEY" || !e.Secret || e.Pattern != "^sk_" || e.Description == "" || !e.IsRequired() {
+		t.Errorf("mapping entry = %+v", e)
+	}
+	if e := kf.Env[2]; e.IsRequired() || e.Default != "info" || len(e.Enum) != 3 {
+		t.Errorf("optional entry = %+v", e)
+	}
+	if names := kf.EnvNames(); len(names) != 3 || names[2] != "LOG_LEVEL" {
+		t.Errorf("EnvNames() = %v", names)
+	}
+
+	out, err := yaml.Marshal(&kf)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(out), "- SMTP_URL\n") || !strings.Contains(string(out), "name: STRIPE_KEY") {
+		t.Errorf("unexpected round-trip output:\n%s", out)
+	}
+}
+
 func TestLoadKyperFileMissing(t *testing.T) {
 	_, _, err := LoadKyperFile("/nonexistent/kyper.yml")
 	if err == nil {
@@ -177,3 +230,57 @@ func TestLoadKyperFileMalformed(t *testing.T) {
 		t.Error("expected error for malformed YAML")
 	}
 }
+
+func TestLoadKyperFileBuildContext(t *testing.T) {
+	dir := t.TempDir()
+	appDir := filepath.Join(dir, "apps", "web")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(appDir, "kyper.yml")
+	content := `name: web
+docker:
+  dockerfile: ./Dockerfile
+build:
+  context: ../..
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kf, _, err := LoadKyperFile(path)
+	if err != nil {
+		t.Fatalf("LoadKyperFile failed: %v", err)
+	}
+	if kf.Dir != appDir {
+		t.Errorf("expected Dir %q, got %q", appDir, kf.Dir)
+	}
+	if kf.ContextDir() != dir {
+		t.Errorf("expected context %q, got %q", dir, kf.ContextDir())
+	}
+	if kf.DockerfilePath() != filepath.Join(appDir, "Dockerfile") {
+		t.Errorf("unexpected dockerfile path: %q", kf.DockerfilePath())
+	}
+	rel, err := kf.DockerfileInContext()
+	if err != nil {
+		t.Fatalf("DockerfileInContext failed: %v", err)
+	}
+	if rel != "apps/web/Dockerfile" {
+		t.Errorf("expected apps/web/Dockerfile, got %q", rel)
+	}
+}
+
+func TestDockerfileInContextOutside(t *testing.T) {
+	kf := &KyperFile{
+		Dir:    "apps/web",
+		Docker: DockerConfig{Dockerfile: "../../Dockerfile"},
+	}
+	if _, err := kf.DockerfileInContext(); err == nil {
+		t.Error("expected error for Dockerfile outside the build context")
+	}
+
+	kf.Build.Context = "../.."
+	if _, err := kf.DockerfileInContext(); err != nil {
+		t.Errorf("expected Dockerfile inside widened context, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFileName is the file that lists the apps in a multi-app repository.
+const WorkspaceFileName = "kyper.workspace.yml"
+
+// DefaultWorkspaceConcurrency bounds how many apps are processed at once when
+// the workspace file doesn't set concurrency.
+const DefaultWorkspaceConcurrency = 4
+
+// Workspace lists app directories, each containing its own kyper.yml.
+type Workspace struct {
+	Apps        []string `yaml:"apps"`
+	Concurrency int      `yaml:"concurrency,omitempty"`
+
+	// Dir is the directory containing the workspace file. App paths resolve
+	// against it.
+	Dir string `yaml:"-"`
+}
+
+// LoadWorkspace reads and parses a kyper.workspace.yml file.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(ws.Apps) == 0 {
+		return nil, fmt.Errorf("%s lists no apps", path)
+	}
+	if ws.Concurrency < 0 {
+		return nil, fmt.Errorf("%s: concurrency must be positive", path)
+	}
+	ws.Dir = filepath.Dir(path)
+	return &ws, nil
+}
+
+// AppDir returns the directory of app resolved against the workspace file.
+func (ws *Workspace) AppDir(app string) string {
+	if filepath.IsAbs(app) {
+		return app
+	}
+	return filepath.Join(ws.Dir, app)
+}
+
+// KyperFilePath returns the path to app's kyper.yml.
+func (ws *Workspace) KyperFilePath(app string) string {
+	return filepath.Join(ws.AppDir(app), "kyper.yml")
+}
+
+// Limit returns the number of apps to process concurrently.
+func (ws *Workspace) Limit() int {
+	if ws.Concurrency > 0 {
+		return ws.Concurrency
+	}
+	return DefaultWorkspaceConcurrency
+}
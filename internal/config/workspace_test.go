@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, WorkspaceFileName)
+	content := `apps:
+  - apps/web
+  - apps/admin
+concurrency: 2
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := LoadWorkspace(path)
+	if err != nil {
+		t.Fatalf("LoadWorkspace failed: %v", err)
+	}
+	if len(ws.Apps) != 2 || ws.Apps[0] != "apps/web" {
+		t.Errorf("unexpected apps: %v", ws.Apps)
+	}
+	if ws.Limit() != 2 {
+		t.Errorf("expected limit 2, got %d", ws.Limit())
+	}
+	if ws.KyperFilePath("apps/web") != filepath.Join(dir, "apps", "web", "kyper.yml") {
+		t.Errorf("unexpected kyper.yml path: %q", ws.KyperFilePath("apps/web"))
+	}
+}
+
+func TestLoadWorkspaceDefaultConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, WorkspaceFileName)
+	if err := os.WriteFile(path, []byte("apps:\n  - web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := LoadWorkspace(path)
+	if err != nil {
+		t.Fatalf("LoadWorkspace failed: %v", err)
+	}
+	if ws.Limit() != DefaultWorkspaceConcurrency {
+		t.Errorf("expected default limit %d, got %d", DefaultWorkspaceConcurrency, ws.Limit())
+	}
+}
+
+func TestLoadWorkspaceNoApps(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, WorkspaceFileName)
+	if err := os.WriteFile(path, []byte("apps: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadWorkspace(path); err == nil {
+		t.Error("expected error for workspace without apps")
+	}
+}
@@ -0,0 +1,135 @@
+package detect
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// isRange reports whether a version value is a range rather than an exact
+// pin: ">=3.11", "^8.2", "~> 3.2.0" and "3.*" are ranges; "3.3.5",
+// "v20.11.1" and "python-3.11.9" aren't.
+func isRange(s string) bool {
+	return strings.ContainsAny(s, "<>^~*|,!") || strings.Contains(s, ".x")
+}
+
+var (
+	orRegexp   = regexp.MustCompile(`\|\|?`)
+	termRegexp = regexp.MustCompile(`(>=|<=|!=|==|~>|~=|>|<|=|\^|~)?\s*v?(\d+(?:\.\d+)*)(\.[x*])?`)
+)
+
+// Satisfies reports whether version meets constraint. It understands the
+// range syntax of npm, Composer, PEP 440 and RubyGems: comparisons (>=, >,
+// <=, <, =, ==, !=), caret (^), tilde (~, ~>, ~=) and wildcards (8.*, 8.x),
+// joined by commas or spaces (and) or by | and || (or).
+func Satisfies(constraint, version string) bool {
+	v := parseVersion(version)
+	if v == nil {
+		return false
+	}
+	for _, alt := range orRegexp.Split(constraint, -1) {
+		terms := termRegexp.FindAllStringSubmatch(alt, -1)
+		if len(terms) == 0 {
+			continue
+		}
+		ok := true
+		for _, t := range terms {
+			if !satisfiesTerm(v, t[1], parseVersion(t[2]), t[3] != "") {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfiesTerm(v []int, op string, c []int, wildcard bool) bool {
+	if wildcard && (op == "" || op == "=" || op == "==") {
+		return hasPrefix(v, c)
+	}
+	switch op {
+	case "", "=", "==":
+		return hasPrefix(v, c)
+	case "!=":
+		return !hasPrefix(v, c)
+	case ">=":
+		return compareVersions(v, c) >= 0
+	case ">":
+		return compareVersions(v, c) > 0
+	case "<=":
+		return compareVersions(v, c) <= 0
+	case "<":
+		return compareVersions(v, c) < 0
+	case "^":
+		// The first non-zero component is the one that can't change.
+		i := 0
+		for i < len(c)-1 && c[i] == 0 {
+			i++
+		}
+		return compareVersions(v, c) >= 0 && compareVersions(v, bump(c, i)) < 0
+	case "~":
+		// ~1.2 allows 1.2.x; ~1 allows 1.x.
+		i := min(1, len(c)-1)
+		return compareVersions(v, c) >= 0 && compareVersions(v, bump(c, i)) < 0
+	case "~>", "~=":
+		// ~> 3.2.0 allows 3.2.x; ~> 3.2 allows 3.x.
+		if len(c) == 1 {
+			return compareVersions(v, c) >= 0
+		}
+		return compareVersions(v, c) >= 0 && compareVersions(v, bump(c, len(c)-2)) < 0
+	}
+	return false
+}
+
+// parseVersion splits "3.11.2" into its numeric components, or returns nil.
+func parseVersion(s string) []int {
+	var parts []int
+	for _, p := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// compareVersions compares a and b, treating missing components as zero.
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// hasPrefix reports whether v starts with every component of prefix.
+func hasPrefix(v, prefix []int) bool {
+	for i, p := range prefix {
+		if i >= len(v) || v[i] != p {
+			return i >= len(v) && p == 0
+		}
+	}
+	return true
+}
+
+// bump increments component i of c and drops the ones after it.
+func bump(c []int, i int) []int {
+	out := append([]int(nil), c[:i+1]...)
+	out[i]++
+	return out
+}
@@ -0,0 +1,511 @@
+package detect
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Match records a matcher that fired, for kyper detect --explain.
+type Match struct {
+	// Kind is stack, version, runtime, hook, dep, dep version or process.
+	Kind    string `json:"kind"`
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Matcher string `json:"matcher"`
+	Value   string `json:"value,omitempty"`
+	Origin  string `json:"origin"`
+}
+
+// String describes what the matcher looks for.
+func (m *Matcher) String() string {
+	switch {
+	case m.Package != "":
+		return "package " + m.Package
+	case m.Contains != "":
+		return "contains " + m.Contains
+	case m.Regex != "":
+		return "regex " + m.Regex
+	case m.Key != "":
+		return "key " + m.Key
+	}
+	return "exists"
+}
+
+// detector evaluates rules against one directory, caching file reads and
+// recording every match.
+type detector struct {
+	rules    *Rules
+	dir      string
+	files    map[string][]byte
+	runtimes map[string]RuntimeResult
+	trace    []Match
+}
+
+func (r *Rules) detector(dir string) *detector {
+	return &detector{rules: r, dir: dir, files: map[string][]byte{}, runtimes: map[string]RuntimeResult{}}
+}
+
+// DetectStack identifies the application framework/stack from project files.
+func (r *Rules) DetectStack(dir string) []StackResult {
+	return r.detector(dir).stacks()
+}
+
+// DetectRuntime returns the version of language pinned in dir, or a result
+// with an empty Version if nothing pins one.
+func (r *Rules) DetectRuntime(dir, language string) RuntimeResult {
+	return r.detector(dir).runtime(language)
+}
+
+// DetectDeps scans project files for infrastructure dependencies.
+func (r *Rules) DetectDeps(dir string) []DepResult {
+	return r.detector(dir).deps()
+}
+
+// SuggestDepVersions reads lockfiles to suggest versions for detected deps.
+func (r *Rules) SuggestDepVersions(dir string, deps []DepResult) []VersionSuggestion {
+	return r.detector(dir).depVersions(deps)
+}
+
+// DetectProcesses scans a directory for process definitions.
+func (r *Rules) DetectProcesses(dir string) []ProcessResult {
+	return r.detector(dir).processes()
+}
+
+// Explain runs every detection over dir and returns the matchers that fired,
+// in the order they were evaluated.
+func (r *Rules) Explain(dir string) []Match {
+	d := r.detector(dir)
+	d.stacks()
+	d.processes()
+	d.depVersions(d.deps())
+	return d.trace
+}
+
+func (d *detector) stacks() []StackResult {
+	var results []StackResult
+	groups := map[string]bool{}
+	for _, rule := range d.rules.Stacks {
+		if rule.Group != "" && groups[rule.Group] {
+			continue
+		}
+		file, _, ok := d.first("stack", rule.Name, rule.Match)
+		if !ok {
+			continue
+		}
+		if rule.Group != "" {
+			groups[rule.Group] = true
+		}
+		r := StackResult{Name: rule.Name, Source: file}
+		if v, _, src, ok := d.firstVersion("version", rule.Name, rule.Version); ok {
+			r.Version, r.VersionSource = majorMinor(v), src
+		}
+		if rule.Language != "" {
+			r.Runtime = d.runtime(rule.Language)
+		}
+		if rule.Hook != "" {
+			if len(rule.HookIf) == 0 {
+				r.Hook = rule.Hook
+			} else if _, _, ok := d.first("hook", rule.Name, rule.HookIf); ok {
+				r.Hook = rule.Hook
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func (d *detector) runtime(language string) RuntimeResult {
+	if rt, ok := d.runtimes[language]; ok {
+		return rt
+	}
+	rt := RuntimeResult{Language: language}
+	if rule := d.rules.runtime(language); rule != nil {
+		if v, raw, src, ok := d.firstVersion("runtime", language, rule.Version); ok {
+			rt.Version, rt.Source = v, src
+			if isRange(raw) {
+				rt.Constraint = strings.TrimSpace(raw)
+			}
+		}
+	}
+	d.runtimes[language] = rt
+	return rt
+}
+
+func (d *detector) deps() []DepResult {
+	var results []DepResult
+	for _, rule := range d.rules.Deps {
+		if file, _, ok := d.first("dep", rule.Name, rule.Match); ok {
+			results = append(results, DepResult{Name: rule.Name, Source: file})
+		}
+	}
+	return results
+}
+
+func (d *detector) depVersions(deps []DepResult) []VersionSuggestion {
+	var results []VersionSuggestion
+	for _, dep := range deps {
+		rule := d.rules.dep(dep.Name)
+		if rule == nil {
+			continue
+		}
+		if v, _, src, ok := d.firstVersion("dep version", dep.Name, rule.Version); ok {
+			results = append(results, VersionSuggestion{
+				Dep:     dep.Name,
+				Version: strings.Split(v, ".")[0],
+				Source:  src,
+			})
+		}
+	}
+	return results
+}
+
+func (d *detector) processes() []ProcessResult {
+	var results []ProcessResult
+	seen := map[string]bool{}
+	for _, rule := range d.rules.Processes {
+		if rule.Procfile != "" {
+			data, ok := d.read(rule.Procfile)
+			if !ok {
+				continue
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				name := strings.TrimSpace(parts[0])
+				cmd := strings.TrimSpace(parts[1])
+				seen[name] = true
+				results = append(results, ProcessResult{Name: name, Command: cmd, Source: rule.Procfile})
+				d.record(Match{Kind: "process", Rule: name, File: rule.Procfile, Matcher: "procfile", Value: cmd, Origin: rule.Origin})
+			}
+			continue
+		}
+		if seen[rule.Name] {
+			continue
+		}
+		for i := range rule.Match {
+			m := &rule.Match[i]
+			file, value, ok := d.eval(m)
+			cmd := rule.Command
+			if cmd == "" {
+				cmd = strings.TrimSpace(value)
+			}
+			if !ok || cmd == "" {
+				continue
+			}
+			seen[rule.Name] = true
+			results = append(results, ProcessResult{Name: rule.Name, Command: cmd, Source: file})
+			d.record(Match{Kind: "process", Rule: rule.Name, File: file, Matcher: m.String(), Value: cmd, Origin: m.Origin})
+			break
+		}
+	}
+	return results
+}
+
+// first returns the file of the first matcher in ms that fires.
+func (d *detector) first(kind, rule string, ms []Matcher) (file, value string, ok bool) {
+	for i := range ms {
+		m := &ms[i]
+		if file, value, ok := d.eval(m); ok {
+			d.record(Match{Kind: kind, Rule: rule, File: file, Matcher: m.String(), Value: value, Origin: m.Origin})
+			return file, value, true
+		}
+	}
+	return "", "", false
+}
+
+// firstVersion returns the version from the first matcher in ms whose value
+// holds one, along with the raw value it came from.
+func (d *detector) firstVersion(kind, rule string, ms []Matcher) (version, raw, file string, ok bool) {
+	for i := range ms {
+		m := &ms[i]
+		file, value, ok := d.eval(m)
+		if !ok {
+			continue
+		}
+		// Gradle writes JavaVersion.VERSION_1_8 for 1.8.
+		if v := constraintVersion(strings.ReplaceAll(value, "_", ".")); v != "" {
+			desc := m.String()
+			if desc == "exists" {
+				desc = "first line"
+			}
+			d.record(Match{Kind: kind, Rule: rule, File: file, Matcher: desc, Value: v, Origin: m.Origin})
+			return v, value, file, true
+		}
+	}
+	return "", "", "", false
+}
+
+func (d *detector) record(m Match) {
+	d.trace = append(d.trace, m)
+}
+
+// eval tests m, returning the project-relative file it matched and its
+// value.
+func (d *detector) eval(m *Matcher) (file, value string, ok bool) {
+	file = m.File
+	if strings.ContainsAny(file, "*?[") {
+		matches, _ := filepath.Glob(filepath.Join(d.dir, file))
+		if len(matches) == 0 {
+			return "", "", false
+		}
+		if file, _ = filepath.Rel(d.dir, matches[0]); file == "" {
+			return "", "", false
+		}
+	}
+	data, ok := d.read(file)
+	if !ok {
+		return "", "", false
+	}
+
+	switch {
+	case m.Package != "":
+		found, version := lookupPackage(filepath.Base(file), data, m.Package)
+		return file, version, found
+	case m.Contains != "":
+		return file, "", bytes.Contains(bytes.ToLower(data), []byte(strings.ToLower(m.Contains)))
+	case m.re != nil:
+		sub := m.re.FindSubmatch(data)
+		if sub == nil {
+			return "", "", false
+		}
+		if len(sub) > 1 {
+			return file, string(sub[1]), true
+		}
+		return file, string(sub[0]), true
+	case m.Key != "":
+		v, ok := jsonKey(data, m.Key)
+		return file, v, ok
+	}
+	return file, firstLineOf(data), true
+}
+
+func (d *detector) read(file string) ([]byte, bool) {
+	if data, ok := d.files[file]; ok {
+		return data, data != nil
+	}
+	data, err := os.ReadFile(filepath.Join(d.dir, file))
+	if err != nil {
+		d.files[file] = nil
+		return nil, false
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	d.files[file] = data
+	return data, true
+}
+
+func firstLineOf(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// jsonKey reads a dotted key from a JSON object as a string.
+func jsonKey(data []byte, key string) (string, bool) {
+	var v interface{}
+	if json.Unmarshal(data, &v) != nil {
+		return "", false
+	}
+	for _, part := range strings.Split(key, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if v, ok = obj[part]; !ok {
+			return "", false
+		}
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// lookupPackage reports whether a manifest or lockfile declares pkg, and the
+// version or constraint it gives.
+func lookupPackage(name string, data []byte, pkg string) (bool, string) {
+	switch name {
+	case "Gemfile":
+		content := string(data)
+		return strings.Contains(content, "'"+pkg+"'") || strings.Contains(content, `"`+pkg+`"`), ""
+	case "Gemfile.lock":
+		return gemfileLockGem(data, pkg)
+	case "package.json":
+		return jsonDependency(data, pkg, "dependencies", "devDependencies")
+	case "composer.json":
+		return jsonDependency(data, pkg, "require", "require-dev")
+	case "package-lock.json":
+		return packageLockVersion(data, pkg)
+	case "composer.lock":
+		return composerLockVersion(data, pkg)
+	case "requirements.txt", "pyproject.toml", "Pipfile":
+		return pythonPackage(data, pkg)
+	case "Cargo.toml":
+		return cargoDependency(data, pkg)
+	case "Cargo.lock":
+		return cargoLockVersion(data, pkg)
+	case "mix.exs":
+		return mixDependency(data, pkg)
+	case "mix.lock":
+		return mixLockVersion(data, pkg)
+	}
+	return bytes.Contains(data, []byte(pkg)), ""
+}
+
+// gemfileLockGem reads gem's resolved version from the specs in a
+// Gemfile.lock, where top-level entries are indented four spaces.
+func gemfileLockGem(data []byte, gem string) (bool, string) {
+	prefix := "    " + gem + " ("
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, prefix); ok {
+			return true, strings.TrimSuffix(strings.TrimSpace(v), ")")
+		}
+	}
+	return false, ""
+}
+
+// jsonDependency reads name's version constraint from the given dependency
+// maps of a package.json or composer.json.
+func jsonDependency(data []byte, name string, keys ...string) (bool, string) {
+	var pkg map[string]interface{}
+	if json.Unmarshal(data, &pkg) != nil {
+		return false, ""
+	}
+	v, ok := mergeJSONMaps(pkg, keys...)[name]
+	s, _ := v.(string)
+	return ok, s
+}
+
+// packageLockVersion reads name's version from package-lock.json, either
+// lockfile v2/v3's packages map or v1's dependencies map.
+func packageLockVersion(data []byte, name string) (bool, string) {
+	var lock struct {
+		Packages     map[string]struct{ Version string } `json:"packages"`
+		Dependencies map[string]struct{ Version string } `json:"dependencies"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return false, ""
+	}
+	if p, ok := lock.Packages["node_modules/"+name]; ok {
+		return true, p.Version
+	}
+	if p, ok := lock.Dependencies[name]; ok {
+		return true, p.Version
+	}
+	return false, ""
+}
+
+func composerLockVersion(data []byte, name string) (bool, string) {
+	type lockPackage struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var lock struct {
+		Packages    []lockPackage `json:"packages"`
+		PackagesDev []lockPackage `json:"packages-dev"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return false, ""
+	}
+	for _, p := range append(lock.Packages, lock.PackagesDev...) {
+		if p.Name == name {
+			return true, p.Version
+		}
+	}
+	return false, ""
+}
+
+// pythonPackage finds pkg in requirements.txt, pyproject.toml or Pipfile
+// lines: `Django==5.0.1`, `"django>=5.0",` and `django = "^5.0"` all
+// declare django, while `django-environ` doesn't.
+func pythonPackage(data []byte, pkg string) (bool, string) {
+	re := regexp.MustCompile(`(?i)^(?:.*[\[,]\s*)?["']?` + regexp.QuoteMeta(pkg) + `(?:\[[^\]]*\])?` +
+		`(?:["']?\s*[=<>~^!]=?\s*["'^~>=<v ]*(\d+(?:\.\d+){0,2})|["']|\s*(?:[;@=]|$))`)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := re.FindStringSubmatch(line); m != nil {
+			return true, m[1]
+		}
+	}
+	return false, ""
+}
+
+// cargoDependency finds crate in Cargo.toml, either `crate = "0.7"`,
+// `crate = { version = "0.7", ... }` or a `[dependencies.crate]` table.
+func cargoDependency(data []byte, crate string) (bool, string) {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "[dependencies."+crate+"]" {
+			for _, next := range lines[i+1:] {
+				next = strings.TrimSpace(next)
+				if strings.HasPrefix(next, "[") {
+					break
+				}
+				if v, ok := strings.CutPrefix(next, "version"); ok {
+					return true, strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(v), "=")), `"`)
+				}
+			}
+			return true, ""
+		}
+		v, ok := strings.CutPrefix(line, crate)
+		if !ok {
+			continue
+		}
+		if v = strings.TrimSpace(v); !strings.HasPrefix(v, "=") {
+			continue
+		}
+		v = strings.TrimSpace(v[1:])
+		if i := strings.Index(v, "version"); strings.HasPrefix(v, "{") && i >= 0 {
+			v = v[i+len("version"):]
+		}
+		return true, constraintVersion(v)
+	}
+	return false, ""
+}
+
+// cargoLockVersion reads crate's resolved version from Cargo.lock.
+func cargoLockVersion(data []byte, crate string) (bool, string) {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == `name = "`+crate+`"` && i+1 < len(lines) {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(lines[i+1]), "version = "); ok {
+				return true, strings.Trim(v, `"`)
+			}
+		}
+	}
+	return false, ""
+}
+
+// mixDependency finds a `{:pkg, "~> 1.7"}` entry in mix.exs.
+func mixDependency(data []byte, pkg string) (bool, string) {
+	re := regexp.MustCompile(`\{:` + regexp.QuoteMeta(pkg) + `\s*,\s*(?:"([^"]*)")?`)
+	if m := re.FindSubmatch(data); m != nil {
+		return true, string(m[1])
+	}
+	return false, ""
+}
+
+// mixLockVersion reads a hex package's version from mix.lock, where entries
+// look like `"phoenix": {:hex, :phoenix, "1.7.14", ...}`.
+func mixLockVersion(data []byte, pkg string) (bool, string) {
+	prefix := `"` + pkg + `": {:hex, :` + pkg + `, "`
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+			version, _, _ := strings.Cut(v, `"`)
+			return true, version
+		}
+	}
+	return false, ""
+}
@@ -0,0 +1,365 @@
+package detect
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yml
+var builtinRules []byte
+
+// BuiltinOrigin is the Origin of the rules compiled into kyper.
+const BuiltinOrigin = "built-in"
+
+// Rules is a detection rule registry: the built-in rules plus any the user
+// adds in ~/.kyper/detect.d.
+type Rules struct {
+	Stacks    []StackRule   `yaml:"stacks"`
+	Runtimes  []RuntimeRule `yaml:"runtimes"`
+	Deps      []DepRule     `yaml:"deps"`
+	Processes []ProcessRule `yaml:"processes"`
+
+	// Files lists the user rule files that were loaded.
+	Files []string `yaml:"-"`
+}
+
+// StackRule detects a framework. It also carries the stack's init defaults:
+// the deploy hook, the health check path and the .kyperignore entries.
+type StackRule struct {
+	Name string `yaml:"name"`
+	// Label heads the stack's .kyperignore section; it defaults to Name.
+	Label string `yaml:"label,omitempty"`
+	// Group makes stacks exclusive: only the first in a group is detected.
+	Group string `yaml:"group,omitempty"`
+	// Language is the runtime the stack runs on, a RuntimeRule language.
+	Language string    `yaml:"language,omitempty"`
+	Match    []Matcher `yaml:"match"`
+	Version  []Matcher `yaml:"version,omitempty"`
+	Hook     string    `yaml:"hook,omitempty"`
+	// HookIf confirms the hook's tool is part of the project: when set, the
+	// hook is only suggested if one of these matchers fires.
+	HookIf     []Matcher `yaml:"hook_if,omitempty"`
+	HealthPath string    `yaml:"health_path,omitempty"`
+	Ignore     []string  `yaml:"ignore,omitempty"`
+}
+
+// RuntimeRule lists where a language's version can be pinned, in order.
+type RuntimeRule struct {
+	Language string    `yaml:"language"`
+	Version  []Matcher `yaml:"version"`
+}
+
+// DepRule detects an infrastructure dep and the version its lockfile
+// suggests.
+type DepRule struct {
+	Name    string    `yaml:"name"`
+	Match   []Matcher `yaml:"match"`
+	Version []Matcher `yaml:"version,omitempty"`
+}
+
+// ProcessRule detects a process. A Procfile rule reads every process from a
+// Procfile-format file; otherwise the rule names one process and takes its
+// command from Command or the first matcher's value.
+type ProcessRule struct {
+	Procfile string    `yaml:"procfile,omitempty"`
+	Name     string    `yaml:"name,omitempty"`
+	Command  string    `yaml:"command,omitempty"`
+	Match    []Matcher `yaml:"match,omitempty"`
+	Origin   string    `yaml:"-"`
+}
+
+// Matcher tests one file, given as a path relative to the project or a
+// glob. It fires when the file exists and, if set, Package, Contains, Regex
+// or Key matches.
+type Matcher struct {
+	File string `yaml:"file"`
+	// Package is a dependency declared in a manifest or lockfile such as
+	// package.json, Gemfile.lock, requirements.txt or Cargo.toml. The value
+	// is its version. In other files it's a plain text search.
+	Package string `yaml:"package,omitempty"`
+	// Contains is case-insensitive text to find.
+	Contains string `yaml:"contains,omitempty"`
+	// Regex must match; the value is its first group, or the whole match.
+	Regex string `yaml:"regex,omitempty"`
+	// Key is a dotted path into a JSON file, such as engines.node.
+	Key string `yaml:"key,omitempty"`
+
+	// Origin is the rules file the matcher came from.
+	Origin string `yaml:"-"`
+	re     *regexp.Regexp
+}
+
+// Stack returns the rule for the named stack, or nil.
+func (r *Rules) Stack(name string) *StackRule {
+	for i := range r.Stacks {
+		if r.Stacks[i].Name == name {
+			return &r.Stacks[i]
+		}
+	}
+	return nil
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultRules *Rules
+	defaultErr   error
+)
+
+// Default returns the built-in rules extended by ~/.kyper/detect.d. It loads
+// them once; the error reports user rule files that were skipped.
+func Default() (*Rules, error) {
+	defaultOnce.Do(func() {
+		dir, err := config.RulesDir()
+		if err != nil {
+			dir = ""
+		}
+		defaultRules, defaultErr = LoadRules(dir)
+	})
+	return defaultRules, defaultErr
+}
+
+// LoadRules reads the built-in rules and extends them with every *.yml and
+// *.yaml file in userDir, in name order. A file that fails to parse or
+// validate is skipped and reported in the returned error; the rules are
+// still usable.
+func LoadRules(userDir string) (*Rules, error) {
+	rules, err := parseRules(builtinRules, BuiltinOrigin)
+	if err != nil {
+		panic(fmt.Sprintf("built-in detection rules: %v", err))
+	}
+	if userDir == "" {
+		return rules, nil
+	}
+
+	var paths []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(userDir, pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		extra, err := loadRulesFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		rules.merge(extra)
+		rules.Files = append(rules.Files, path)
+	}
+	return rules, errors.Join(errs...)
+}
+
+func loadRulesFile(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRules(data, path)
+}
+
+func parseRules(data []byte, origin string) (*Rules, error) {
+	var r Rules
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&r); err != nil {
+		return nil, err
+	}
+	if err := r.prepare(origin); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// prepare validates the rules and compiles their regexes.
+func (r *Rules) prepare(origin string) error {
+	for i := range r.Stacks {
+		s := &r.Stacks[i]
+		if s.Name == "" {
+			return fmt.Errorf("stacks[%d]: name is required", i)
+		}
+		if err := prepareMatchers(s.Match, origin, "stack "+s.Name); err != nil {
+			return err
+		}
+		if err := prepareMatchers(s.Version, origin, "stack "+s.Name+" version"); err != nil {
+			return err
+		}
+		if err := prepareMatchers(s.HookIf, origin, "stack "+s.Name+" hook_if"); err != nil {
+			return err
+		}
+	}
+	for i := range r.Runtimes {
+		rt := &r.Runtimes[i]
+		if rt.Language == "" {
+			return fmt.Errorf("runtimes[%d]: language is required", i)
+		}
+		if len(rt.Version) == 0 {
+			return fmt.Errorf("runtime %s: version needs at least one matcher", rt.Language)
+		}
+		if err := prepareMatchers(rt.Version, origin, "runtime "+rt.Language); err != nil {
+			return err
+		}
+	}
+	for i := range r.Deps {
+		d := &r.Deps[i]
+		if d.Name == "" {
+			return fmt.Errorf("deps[%d]: name is required", i)
+		}
+		if err := prepareMatchers(d.Match, origin, "dep "+d.Name); err != nil {
+			return err
+		}
+		if err := prepareMatchers(d.Version, origin, "dep "+d.Name+" version"); err != nil {
+			return err
+		}
+	}
+	for i := range r.Processes {
+		p := &r.Processes[i]
+		p.Origin = origin
+		switch {
+		case p.Procfile != "":
+			if p.Name != "" || p.Command != "" || len(p.Match) > 0 {
+				return fmt.Errorf("processes[%d]: a procfile rule can't also set name, command or match", i)
+			}
+			if err := checkRelative(p.Procfile); err != nil {
+				return fmt.Errorf("processes[%d]: %w", i, err)
+			}
+		case p.Name == "":
+			return fmt.Errorf("processes[%d]: name or procfile is required", i)
+		case len(p.Match) == 0:
+			return fmt.Errorf("process %s: match needs at least one matcher", p.Name)
+		}
+		if err := prepareMatchers(p.Match, origin, "process "+p.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prepareMatchers(ms []Matcher, origin, what string) error {
+	for i := range ms {
+		m := &ms[i]
+		m.Origin = origin
+		if m.File == "" {
+			return fmt.Errorf("%s: matcher %d needs a file", what, i+1)
+		}
+		if err := checkRelative(m.File); err != nil {
+			return fmt.Errorf("%s: %w", what, err)
+		}
+		set := 0
+		for _, v := range []string{m.Package, m.Contains, m.Regex, m.Key} {
+			if v != "" {
+				set++
+			}
+		}
+		if set > 1 {
+			return fmt.Errorf("%s: matcher on %s sets more than one of package, contains, regex and key", what, m.File)
+		}
+		if m.Regex != "" {
+			re, err := regexp.Compile(m.Regex)
+			if err != nil {
+				return fmt.Errorf("%s: invalid regex for %s: %w", what, m.File, err)
+			}
+			m.re = re
+		}
+	}
+	return nil
+}
+
+func checkRelative(path string) error {
+	if filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+		return fmt.Errorf("%q must be relative to the project directory", path)
+	}
+	return nil
+}
+
+// merge extends r with o. A rule whose name (or language, for runtimes)
+// already exists gets o's matchers appended and its other fields replaced
+// where o sets them; new rules are appended.
+func (r *Rules) merge(o *Rules) {
+	for _, s := range o.Stacks {
+		existing := r.Stack(s.Name)
+		if existing == nil {
+			r.Stacks = append(r.Stacks, s)
+			continue
+		}
+		existing.Match = append(existing.Match, s.Match...)
+		existing.Version = append(existing.Version, s.Version...)
+		existing.HookIf = append(existing.HookIf, s.HookIf...)
+		replace(&existing.Label, s.Label)
+		replace(&existing.Group, s.Group)
+		replace(&existing.Language, s.Language)
+		replace(&existing.Hook, s.Hook)
+		replace(&existing.HealthPath, s.HealthPath)
+		if s.Ignore != nil {
+			existing.Ignore = s.Ignore
+		}
+	}
+	for _, rt := range o.Runtimes {
+		if existing := r.runtime(rt.Language); existing != nil {
+			existing.Version = append(existing.Version, rt.Version...)
+		} else {
+			r.Runtimes = append(r.Runtimes, rt)
+		}
+	}
+	for _, d := range o.Deps {
+		if existing := r.dep(d.Name); existing != nil {
+			existing.Match = append(existing.Match, d.Match...)
+			existing.Version = append(existing.Version, d.Version...)
+		} else {
+			r.Deps = append(r.Deps, d)
+		}
+	}
+	for _, p := range o.Processes {
+		if existing := r.process(p.Name); p.Name != "" && existing != nil {
+			existing.Match = append(existing.Match, p.Match...)
+			replace(&existing.Command, p.Command)
+		} else {
+			r.Processes = append(r.Processes, p)
+		}
+	}
+}
+
+func replace(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func (r *Rules) runtime(language string) *RuntimeRule {
+	for i := range r.Runtimes {
+		if r.Runtimes[i].Language == language {
+			return &r.Runtimes[i]
+		}
+	}
+	return nil
+}
+
+func (r *Rules) dep(name string) *DepRule {
+	for i := range r.Deps {
+		if r.Deps[i].Name == name {
+			return &r.Deps[i]
+		}
+	}
+	return nil
+}
+
+func (r *Rules) process(name string) *ProcessRule {
+	for i := range r.Processes {
+		if r.Processes[i].Name == name {
+			return &r.Processes[i]
+		}
+	}
+	return nil
+}
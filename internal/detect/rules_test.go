@@ -0,0 +1,174 @@
+package detect
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	for _, name := range []string{"rails", "django", "laravel", "go", "next", "prisma"} {
+		if rules.Stack(name) == nil {
+			t.Errorf("expected a built-in %s rule", name)
+		}
+	}
+	if r := rules.Stack("rails"); r.Hook != "bundle exec rails db:migrate" || r.HealthPath != "/up" {
+		t.Errorf("unexpected rails defaults: %+v", r)
+	}
+	if rules.Stack("rails").Match[0].Origin != BuiltinOrigin {
+		t.Errorf("expected built-in origin, got %q", rules.Stack("rails").Match[0].Origin)
+	}
+}
+
+func TestLoadRulesUserDir(t *testing.T) {
+	userDir := t.TempDir()
+	writeFiles(t, userDir, map[string]string{
+		"10-hanami.yml": `stacks:
+  - name: hanami
+    label: Hanami
+    language: ruby
+    match:
+      - {file: Gemfile, package: hanami}
+    hook: bundle exec hanami db migrate
+    health_path: /health
+    ignore: [tmp/]
+deps:
+  - name: postgres
+    match:
+      - {file: Gemfile, package: sequel_pg}
+`,
+		"20-rails.yaml": `stacks:
+  - name: rails
+    health_path: /healthz
+`,
+		"30-broken.yml": `stacks:
+  - name: broken
+    match:
+      - {file: x, regex: "("}
+`,
+		"40-unknown.yml": "stacks:\n  - name: x\n    matches: []\n",
+		"notes.txt":      "ignored",
+	})
+
+	rules, err := LoadRules(userDir)
+	if err == nil || !strings.Contains(err.Error(), "30-broken.yml") || !strings.Contains(err.Error(), "40-unknown.yml") {
+		t.Errorf("expected errors for the broken files, got %v", err)
+	}
+	if len(rules.Files) != 2 {
+		t.Errorf("expected two rule files to load, got %v", rules.Files)
+	}
+	if r := rules.Stack("rails"); r.HealthPath != "/healthz" || r.Hook != "bundle exec rails db:migrate" {
+		t.Errorf("expected rails health path overridden and hook kept, got %+v", r)
+	}
+	if rules.Stack("broken") != nil {
+		t.Error("rules from a broken file should be skipped")
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Gemfile":       "gem 'hanami'\ngem 'sequel_pg'\n",
+		".ruby-version": "3.3.5\n",
+	})
+	stacks := rules.DetectStack(dir)
+	if len(stacks) != 1 || stacks[0].Name != "hanami" || stacks[0].Runtime.Version != "3.3.5" {
+		t.Errorf("expected hanami on ruby 3.3.5, got %+v", stacks)
+	}
+	deps := rules.DetectDeps(dir)
+	if len(deps) != 1 || deps[0].Name != "postgres" {
+		t.Errorf("expected postgres from the extended rule, got %+v", deps)
+	}
+
+	var fired *Match
+	for _, m := range rules.Explain(dir) {
+		if m.Kind == "dep" && m.Rule == "postgres" {
+			fired = &m
+		}
+	}
+	if fired == nil || fired.File != "Gemfile" || fired.Matcher != "package sequel_pg" || fired.Origin != filepath.Join(userDir, "10-hanami.yml") {
+		t.Errorf("expected explain to credit the user rule, got %+v", fired)
+	}
+}
+
+func TestHookIf(t *testing.T) {
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"requirements.txt": "fastapi==0.115.0\n"})
+	if stacks := rules.DetectStack(dir); len(stacks) != 1 || stacks[0].Hook != "" {
+		t.Errorf("expected no hook without alembic.ini, got %+v", stacks)
+	}
+
+	writeFiles(t, dir, map[string]string{"alembic.ini": "[alembic]\n"})
+	if stacks := rules.DetectStack(dir); len(stacks) != 1 || stacks[0].Hook != "alembic upgrade head" {
+		t.Errorf("expected the alembic hook, got %+v", stacks)
+	}
+	var fired bool
+	for _, m := range rules.Explain(dir) {
+		if m.Kind == "hook" && m.Rule == "fastapi" && m.File == "alembic.ini" {
+			fired = true
+		}
+	}
+	if !fired {
+		t.Error("expected explain to show the hook_if match")
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{"stacks:\n  - match: [{file: a}]\n", "name is required"},
+		{"stacks:\n  - name: a\n    match: [{package: x}]\n", "needs a file"},
+		{"deps:\n  - name: a\n    match: [{file: ../secrets}]\n", "must be relative"},
+		{"deps:\n  - name: a\n    match: [{file: a, package: x, contains: y}]\n", "more than one"},
+		{"runtimes:\n  - language: zig\n", "at least one matcher"},
+		{"processes:\n  - procfile: Procfile\n    name: web\n", "procfile rule"},
+		{"processes:\n  - name: worker\n", "at least one matcher"},
+	}
+	for _, tt := range tests {
+		_, err := parseRules([]byte(tt.yaml), "test.yml")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseRules(%q) = %v, want error containing %q", tt.yaml, err, tt.want)
+		}
+	}
+}
+
+func TestProcessRules(t *testing.T) {
+	rules, err := parseRules([]byte(`processes:
+  - procfile: Procfile
+  - name: web
+    match:
+      - {file: Dockerfile, regex: '(?im)^CMD\s+(.+)$'}
+  - name: worker
+    command: bundle exec sidekiq
+    match:
+      - {file: config/sidekiq.yml}
+`), "test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Procfile":           "web: bin/rails server\n",
+		"Dockerfile":         "FROM ruby\nCMD [\"puma\"]\n",
+		"config/sidekiq.yml": ":concurrency: 5\n",
+	})
+
+	got := map[string]string{}
+	for _, p := range rules.DetectProcesses(dir) {
+		got[p.Name] = p.Command + " from " + p.Source
+	}
+	if got["web"] != "bin/rails server from Procfile" {
+		t.Errorf("the Procfile should win for web, got %q", got["web"])
+	}
+	if got["worker"] != "bundle exec sidekiq from config/sidekiq.yml" {
+		t.Errorf("expected the rule's command for worker, got %q", got["worker"])
+	}
+}
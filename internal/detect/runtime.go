@@ -0,0 +1,41 @@
+package detect
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RuntimeResult is a language runtime version pinned by a project.
+type RuntimeResult struct {
+	Language string
+	// Version is the pinned version, or the lowest version a range allows.
+	Version string
+	Source  string
+	// Constraint is the range Version came from, such as ">=3.11" or
+	// "^8.2"; it's empty for an exact pin.
+	Constraint string
+}
+
+// DetectRuntime returns the version of language pinned in dir, or a result
+// with an empty Version if nothing pins one. It uses the default rules.
+func DetectRuntime(dir, language string) RuntimeResult {
+	rules, _ := Default()
+	return rules.DetectRuntime(dir, language)
+}
+
+var versionRegexp = regexp.MustCompile(`\d+(\.\d+){0,2}`)
+
+// constraintVersion returns the first version number in s, so "ruby-3.3.5",
+// "v20.11.1", ">=3.11" and "^8.2|^8.3" give 3.3.5, 20.11.1, 3.11 and 8.2.
+func constraintVersion(s string) string {
+	return versionRegexp.FindString(s)
+}
+
+// majorMinor trims a version to its first two components.
+func majorMinor(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
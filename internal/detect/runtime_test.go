@@ -0,0 +1,127 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectRuntime(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		files    map[string]string
+		want     RuntimeResult
+	}{
+		{"ruby-version", "ruby", map[string]string{".ruby-version": "ruby-3.3.5\n", "Gemfile": "ruby \"3.2.0\"\n"},
+			RuntimeResult{"ruby", "3.3.5", ".ruby-version", ""}},
+		{"tool-versions ruby", "ruby", map[string]string{".tool-versions": "nodejs 20.11.1\nruby 3.3.4\n"},
+			RuntimeResult{"ruby", "3.3.4", ".tool-versions", ""}},
+		{"Gemfile.lock ruby", "ruby", map[string]string{"Gemfile.lock": "GEM\n  specs:\n\nRUBY VERSION\n   ruby 3.3.5p100\n"},
+			RuntimeResult{"ruby", "3.3.5", "Gemfile.lock", ""}},
+		{"Gemfile ruby", "ruby", map[string]string{"Gemfile": "source \"https://rubygems.org\"\nruby \"~> 3.2.0\"\n"},
+			RuntimeResult{"ruby", "3.2.0", "Gemfile", "~> 3.2.0"}},
+		{"python-version", "python", map[string]string{".python-version": "3.12.4\n", "runtime.txt": "python-3.11.9\n"},
+			RuntimeResult{"python", "3.12.4", ".python-version", ""}},
+		{"runtime.txt", "python", map[string]string{"runtime.txt": "python-3.11.9\n"},
+			RuntimeResult{"python", "3.11.9", "runtime.txt", ""}},
+		{"requires-python", "python", map[string]string{"pyproject.toml": "[project]\nname = \"app\"\nrequires-python = \">=3.11\"\n"},
+			RuntimeResult{"python", "3.11", "pyproject.toml", ">=3.11"}},
+		{"poetry python", "python", map[string]string{"pyproject.toml": "[tool.poetry.dependencies]\npython = \"^3.12\"\ndjango = \"^5.0\"\n"},
+			RuntimeResult{"python", "3.12", "pyproject.toml", "^3.12"}},
+		{"nvmrc", "node", map[string]string{".nvmrc": "v20.11.1\n", "package.json": `{"engines": {"node": ">=18"}}`},
+			RuntimeResult{"node", "20.11.1", ".nvmrc", ""}},
+		{"nvmrc alias falls through", "node", map[string]string{".nvmrc": "lts/iron\n", "package.json": `{"engines": {"node": ">=18.17 <23"}}`},
+			RuntimeResult{"node", "18.17", "package.json", ">=18.17 <23"}},
+		{"go directive", "go", map[string]string{"go.mod": "module example.com/app\n\ngo 1.23.2\n"},
+			RuntimeResult{"go", "1.23.2", "go.mod", ""}},
+		{"go toolchain", "go", map[string]string{"go.mod": "module example.com/app\n\ngo 1.22\n\ntoolchain go1.23.4\n"},
+			RuntimeResult{"go", "1.23.4", "go.mod", ""}},
+		{"composer platform", "php", map[string]string{"composer.json": `{"require": {"php": "^8.2"}, "config": {"platform": {"php": "8.3.1"}}}`},
+			RuntimeResult{"php", "8.3.1", "composer.json", ""}},
+		{"composer require", "php", map[string]string{"composer.json": `{"require": {"php": "^8.2|^8.3"}}`},
+			RuntimeResult{"php", "8.2", "composer.json", "^8.2|^8.3"}},
+		{"nothing pinned", "php", nil, RuntimeResult{Language: "php"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			if got := DetectRuntime(dir, tt.language); got != tt.want {
+				t.Errorf("DetectRuntime(%s) = %+v, want %+v", tt.language, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=18", "20", true},
+		{">=3.8", "3.12", true},
+		{">=18.17 <23", "20", true},
+		{">=18.17 <20", "20", false},
+		{">=3.9,<3.12", "3.12", false},
+		{"^3.12", "3.12", true},
+		{"^3.11", "3.12", true},
+		{"^18", "20", false},
+		{"^0.2", "0.3", false},
+		{"^8.2|^8.3", "8.3", true},
+		{"^7.4 || ^8.0", "8.3", true},
+		{"~3.11", "3.12", false},
+		{"~> 3.2.0", "3.3", false},
+		{"~> 3.2", "3.3", true},
+		{"~=3.10", "3.12", true},
+		{"3.*", "3.12", true},
+		{"18.x", "20", false},
+		{"!=3.11", "3.12", true},
+		{"==3.12", "3.12", true},
+		{"lts/iron", "20", false},
+	}
+	for _, tt := range tests {
+		if got := Satisfies(tt.constraint, tt.version); got != tt.want {
+			t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestPythonPackage(t *testing.T) {
+	tests := []struct {
+		line    string
+		found   bool
+		version string
+	}{
+		{"Django==5.0.1", true, "5.0.1"},
+		{`  "django>=4.2,<5.0",`, true, "4.2"},
+		{`django = "^5.0"`, true, "5.0"},
+		{`django = {version = "~5.1", extras = ["argon2"]}`, true, ""},
+		{`dependencies = ["gunicorn", "django>=5.1"]`, true, "5.1"},
+		{"django[argon2]~=5.1.2", true, "5.1.2"},
+		{"django", true, ""},
+		{"django-environ==0.11.2", false, ""},
+		{`description = "A django app"`, false, ""},
+		{"# django==4.0", false, ""},
+	}
+	for _, tt := range tests {
+		found, version := pythonPackage([]byte(tt.line), "django")
+		if found != tt.found || version != tt.version {
+			t.Errorf("pythonPackage(%q) = %v, %q, want %v, %q", tt.line, found, version, tt.found, tt.version)
+		}
+	}
+}
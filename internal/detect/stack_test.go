@@ -120,3 +120,111 @@ func TestDetectEmpty(t *testing.T) {
 		t.Errorf("expected empty results, got %v", results)
 	}
 }
+
+func TestDetectStackVersions(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		stack         string
+		version       string
+		versionSource string
+		runtime       RuntimeResult
+	}{
+		{"rails", map[string]string{
+			"config/application.rb": "",
+			"Gemfile.lock":          "GEM\n  specs:\n    actionpack (8.1.0)\n      rails (= 8.1.0)\n    rails (8.1.0)\n\nRUBY VERSION\n   ruby 3.3.5p100\n",
+		}, "rails", "8.1", "Gemfile.lock", RuntimeResult{"ruby", "3.3.5", "Gemfile.lock", ""}},
+		{"django", map[string]string{
+			"manage.py":        "",
+			"requirements.txt": "django-environ==0.11.2\nDjango==5.0.4\n",
+			"runtime.txt":      "python-3.12.3\n",
+		}, "django", "5.0", "requirements.txt", RuntimeResult{"python", "3.12.3", "runtime.txt", ""}},
+		{"laravel", map[string]string{
+			"artisan":       "",
+			"composer.json": `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`,
+			"composer.lock": `{"packages": [{"name": "laravel/framework", "version": "v11.9.2"}]}`,
+		}, "laravel", "11.9", "composer.lock", RuntimeResult{"php", "8.2", "composer.json", "^8.2"}},
+		{"next", map[string]string{
+			"package.json": `{"dependencies": {"next": "^14.2.3"}, "engines": {"node": "20.x"}}`,
+		}, "next", "14.2", "package.json", RuntimeResult{"node", "20", "package.json", "20.x"}},
+		{"go", map[string]string{
+			"go.mod": "module example.com/app\n\ngo 1.23\n",
+		}, "go", "", "", RuntimeResult{"go", "1.23", "go.mod", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			results := DetectStack(dir)
+			if len(results) == 0 || results[0].Name != tt.stack {
+				t.Fatalf("expected to detect %s, got %+v", tt.stack, results)
+			}
+			r := results[0]
+			if r.Version != tt.version || r.VersionSource != tt.versionSource {
+				t.Errorf("version = %q from %q, want %q from %q", r.Version, r.VersionSource, tt.version, tt.versionSource)
+			}
+			if r.Runtime != tt.runtime {
+				t.Errorf("runtime = %+v, want %+v", r.Runtime, tt.runtime)
+			}
+		})
+	}
+}
+
+func TestDetectMoreStacks(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		stack   string
+		source  string
+		version string
+		runtime RuntimeResult
+	}{
+		{"phoenix", map[string]string{
+			"mix.exs":  "def project do\n  [app: :shop, elixir: \"~> 1.15\"]\nend\ndefp deps do\n  [{:phoenix, \"~> 1.7.14\"}]\nend\n",
+			"mix.lock": "%{\n  \"phoenix\": {:hex, :phoenix, \"1.7.14\", \"abc\", [:mix], [], \"hexpm\", \"def\"},\n}\n",
+		}, "phoenix", "mix.exs", "1.7", RuntimeResult{"elixir", "1.15", "mix.exs", "~> 1.15"}},
+		{"spring maven", map[string]string{
+			"pom.xml": "<project><parent><artifactId>spring-boot-starter-parent</artifactId></parent><properties><java.version>21</java.version></properties></project>",
+		}, "spring", "pom.xml", "", RuntimeResult{"java", "21", "pom.xml", ""}},
+		{"spring gradle", map[string]string{
+			"build.gradle.kts": "plugins {\n  id(\"org.springframework.boot\") version \"3.3.0\"\n}\njava { toolchain { languageVersion = JavaLanguageVersion.of(17) } }\n",
+		}, "spring", "build.gradle.kts", "3.3", RuntimeResult{"java", "17", "build.gradle.kts", ""}},
+		{"fastapi", map[string]string{
+			"requirements.txt": "fastapi-users==13.0.0\nfastapi[standard]==0.111.0\nuvicorn\n",
+		}, "fastapi", "requirements.txt", "0.111", RuntimeResult{Language: "python"}},
+		{"flask", map[string]string{
+			"pyproject.toml": "[project]\nrequires-python = \">=3.11\"\ndependencies = [\n  \"flask>=3.0\",\n]\n",
+		}, "flask", "pyproject.toml", "3.0", RuntimeResult{"python", "3.11", "pyproject.toml", ">=3.11"}},
+		{"axum", map[string]string{
+			"Cargo.toml":          "[package]\nname = \"api\"\n\n[dependencies]\naxum = { version = \"0.7.5\", features = [\"macros\"] }\ntokio = \"1\"\n",
+			"rust-toolchain.toml": "[toolchain]\nchannel = \"1.79.0\"\n",
+		}, "axum", "Cargo.toml", "0.7", RuntimeResult{"rust", "1.79.0", "rust-toolchain.toml", ""}},
+		{"actix", map[string]string{
+			"Cargo.toml": "[dependencies]\nactix-web = \"4\"\n",
+		}, "actix", "Cargo.toml", "4", RuntimeResult{Language: "rust"}},
+		{"dotnet", map[string]string{
+			"Api.csproj": "<Project Sdk=\"Microsoft.NET.Sdk.Web\"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>",
+		}, "dotnet", "Api.csproj", "", RuntimeResult{"dotnet", "8.0", "Api.csproj", ""}},
+		{"nuxt", map[string]string{"package.json": `{"dependencies": {"nuxt": "^3.12.0"}}`}, "nuxt", "package.json", "3.12", RuntimeResult{Language: "node"}},
+		{"sveltekit", map[string]string{"package.json": `{"devDependencies": {"@sveltejs/kit": "^2.5.0"}}`}, "sveltekit", "package.json", "2.5", RuntimeResult{Language: "node"}},
+		{"remix over express", map[string]string{"package.json": `{"dependencies": {"express": "^4", "@remix-run/node": "^2.9.2"}}`}, "remix", "package.json", "2.9", RuntimeResult{Language: "node"}},
+		{"astro", map[string]string{"package.json": `{"dependencies": {"astro": "^4.10.0"}}`}, "astro", "package.json", "4.10", RuntimeResult{Language: "node"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			results := DetectStack(dir)
+			if len(results) != 1 {
+				t.Fatalf("expected one stack, got %+v", results)
+			}
+			r := results[0]
+			if r.Name != tt.stack || r.Source != tt.source || r.Version != tt.version {
+				t.Errorf("got %s %q from %s, want %s %q from %s", r.Name, r.Version, r.Source, tt.stack, tt.version, tt.source)
+			}
+			if r.Runtime != tt.runtime {
+				t.Errorf("runtime = %+v, want %+v", r.Runtime, tt.runtime)
+			}
+		})
+	}
+}
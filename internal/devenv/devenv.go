@@ -0,0 +1,442 @@
+// Package devenv runs an app locally the way Kyper does: each process from
+// kyper.yml as a container of the locally built image, its deps at their
+// pinned versions and the env vars Kyper injects. It does so by generating
+// an ephemeral Docker Compose project.
+package devenv
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ContainerPort is the PORT injected into app containers.
+	ContainerPort = 8080
+
+	// HookService is the service hooks run in. It's the web process when
+	// there is one.
+	HookService = "web"
+
+	dbUser     = "kyper"
+	dbPassword = "kyper"
+
+	s3AccessKey = "kyper"
+	s3SecretKey = "kyper-local-secret"
+	s3Port      = 8333
+
+	seaweedImage = "chrislusf/seaweedfs:3.80"
+	s3InitName   = "s3-init"
+)
+
+// elasticsearchTags maps a pinned major version to a concrete image tag;
+// Elastic doesn't publish major-only tags.
+var elasticsearchTags = map[string]string{
+	"8": "8.15.3",
+}
+
+// Options configures a generated project.
+type Options struct {
+	// Image is the locally built app image, e.g. kyper-local/my-app:1.0.0.
+	Image string
+	// HostPort publishes the web process on localhost; 0 leaves it
+	// unpublished.
+	HostPort int
+	// Env holds extra vars for app containers, e.g. from .env. Injected vars
+	// win over these, as they do on Kyper.
+	Env map[string]string
+	// Engine is the container CLI used to run compose (default "docker").
+	Engine string
+}
+
+// Project is a generated compose project on disk.
+type Project struct {
+	Name   string
+	Dir    string
+	Engine string
+
+	// Services lists the app process services, sorted.
+	Services []string
+	// Env is the environment every app container gets.
+	Env map[string]string
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type composeFile struct {
+	Name     string              `yaml:"name"`
+	Services map[string]*service `yaml:"services"`
+}
+
+type service struct {
+	Image       string                `yaml:"image"`
+	PullPolicy  string                `yaml:"pull_policy,omitempty"`
+	Command     []string              `yaml:"command,omitempty"`
+	Environment map[string]string     `yaml:"environment,omitempty"`
+	Ports       []string              `yaml:"ports,omitempty"`
+	DependsOn   map[string]dependency `yaml:"depends_on,omitempty"`
+	Healthcheck *healthcheck          `yaml:"healthcheck,omitempty"`
+	Restart     string                `yaml:"restart,omitempty"`
+}
+
+type dependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type healthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval"`
+	Timeout  string   `yaml:"timeout"`
+	Retries  int      `yaml:"retries"`
+}
+
+// New generates a compose project for kf and writes it to a temp dir.
+// Call Close to tear it down and remove the dir.
+func New(kf *config.KyperFile, slug string, opts Options) (*Project, error) {
+	cf, env, err := generate(kf, slug, opts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(cf)
+	if err != nil {
+		return nil, fmt.Errorf("generating compose file: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "kyper-dev-"+slug+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating project dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "compose.yml"), data, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("writing compose file: %w", err)
+	}
+
+	engine := opts.Engine
+	if engine == "" {
+		engine = "docker"
+	}
+	var services []string
+	for name := range kf.Processes {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+
+	return &Project{
+		Name:     cf.Name,
+		Dir:      dir,
+		Engine:   engine,
+		Services: services,
+		Env:      env,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	}, nil
+}
+
+func generate(kf *config.KyperFile, slug string, opts Options) (*composeFile, map[string]string, error) {
+	if opts.Image == "" {
+		return nil, nil, fmt.Errorf("no app image given")
+	}
+	cf := &composeFile{
+		Name:     "kyper-dev-" + slug,
+		Services: map[string]*service{},
+	}
+
+	// Deps first: app services depend on all of them.
+	dependsOn := map[string]dependency{}
+	for _, dep := range kf.Deps {
+		svc, err := depService(dep, slug)
+		if err != nil {
+			return nil, nil, err
+		}
+		cf.Services[dep.Name] = svc
+		cond := "service_healthy"
+		if svc.Healthcheck == nil {
+			cond = "service_started"
+		}
+		dependsOn[dep.Name] = dependency{Condition: cond}
+
+		if dep.Name == "s3" {
+			cf.Services[s3InitName] = s3InitService(slug)
+			dependsOn[s3InitName] = dependency{Condition: "service_completed_successfully"}
+		}
+	}
+
+	env, err := InjectedEnv(kf, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	appEnv := make(map[string]string, len(opts.Env)+len(env))
+	for k, v := range opts.Env {
+		appEnv[k] = v
+	}
+	for k, v := range env {
+		appEnv[k] = v
+	}
+
+	for name, command := range kf.Processes {
+		if _, taken := cf.Services[name]; taken {
+			return nil, nil, fmt.Errorf("process %q has the same name as a dep — rename it in kyper.yml", name)
+		}
+		svc := &service{
+			Image:       opts.Image,
+			PullPolicy:  "never",
+			Command:     []string{"sh", "-c", escape(command)},
+			Environment: escapeEnv(appEnv),
+		}
+		if len(dependsOn) > 0 {
+			svc.DependsOn = dependsOn
+		}
+		if name == "web" && opts.HostPort > 0 {
+			svc.Ports = []string{fmt.Sprintf("127.0.0.1:%d:%d", opts.HostPort, ContainerPort)}
+		}
+		cf.Services[name] = svc
+	}
+	if _, ok := cf.Services[HookService]; !ok {
+		return nil, nil, fmt.Errorf("kyper.yml has no web process")
+	}
+
+	return cf, appEnv, nil
+}
+
+// DepVersion returns the version a dep runs at: the pinned one, or the
+// newest Kyper allows.
+func DepVersion(dep config.DepEntry) string {
+	if dep.Version != "" {
+		return dep.Version
+	}
+	allowed := kyperfile.AllowedDepVersions[dep.Name]
+	if len(allowed) == 0 {
+		return ""
+	}
+	return allowed[len(allowed)-1]
+}
+
+func depService(dep config.DepEntry, slug string) (*service, error) {
+	version := DepVersion(dep)
+	check := func(test ...string) *healthcheck {
+		return &healthcheck{Test: append([]string{"CMD"}, test...), Interval: "2s", Timeout: "5s", Retries: 60}
+	}
+
+	switch dep.Name {
+	case "postgres":
+		return &service{
+			Image: "postgres:" + version + "-alpine",
+			Environment: map[string]string{
+				"POSTGRES_USER":     dbUser,
+				"POSTGRES_PASSWORD": dbPassword,
+				"POSTGRES_DB":       dbName(slug),
+			},
+			Healthcheck: check("pg_isready", "-U", dbUser, "-d", dbName(slug)),
+		}, nil
+	case "mysql":
+		return &service{
+			Image: "mysql:" + version,
+			Environment: map[string]string{
+				"MYSQL_USER":          dbUser,
+				"MYSQL_PASSWORD":      dbPassword,
+				"MYSQL_ROOT_PASSWORD": dbPassword,
+				"MYSQL_DATABASE":      dbName(slug),
+			},
+			Healthcheck: check("mysqladmin", "ping", "-h", "127.0.0.1", "-u"+dbUser, "-p"+dbPassword),
+		}, nil
+	case "redis":
+		return &service{
+			Image:       "redis:" + version + "-alpine",
+			Healthcheck: check("redis-cli", "ping"),
+		}, nil
+	case "elasticsearch":
+		tag, ok := elasticsearchTags[version]
+		if !ok {
+			return nil, fmt.Errorf("no local image for elasticsearch %s", version)
+		}
+		return &service{
+			Image: "docker.elastic.co/elasticsearch/elasticsearch:" + tag,
+			Environment: map[string]string{
+				"discovery.type":         "single-node",
+				"xpack.security.enabled": "false",
+				"ES_JAVA_OPTS":           "-Xms512m -Xmx512m",
+			},
+			Healthcheck: check("curl", "-fs", "http://localhost:9200/_cluster/health"),
+		}, nil
+	case "opensearch":
+		return &service{
+			Image: "opensearchproject/opensearch:" + version,
+			Environment: map[string]string{
+				"discovery.type":              "single-node",
+				"DISABLE_SECURITY_PLUGIN":     "true",
+				"DISABLE_INSTALL_DEMO_CONFIG": "true",
+				"OPENSEARCH_JAVA_OPTS":        "-Xms512m -Xmx512m",
+			},
+			Healthcheck: check("curl", "-fs", "http://localhost:9200/_cluster/health"),
+		}, nil
+	case "s3":
+		return &service{
+			Image:   seaweedImage,
+			Command: []string{"server", "-dir=/data", "-s3", fmt.Sprintf("-s3.port=%d", s3Port)},
+		}, nil
+	}
+	return nil, fmt.Errorf("dep %q can't be run locally", dep.Name)
+}
+
+// s3InitService creates the app's bucket once SeaweedFS is accepting
+// commands, retrying for up to a minute.
+func s3InitService(slug string) *service {
+	script := fmt.Sprintf(`for i in $(seq 60); do echo "s3.bucket.create -name %s" | weed shell -master=s3:9333 && exit 0; sleep 1; done; exit 1`, slug)
+	return &service{
+		Image:      seaweedImage,
+		Command:    []string{"sh", "-c", escape(script)},
+		DependsOn:  map[string]dependency{"s3": {Condition: "service_started"}},
+		Restart:    "no",
+		PullPolicy: "missing",
+	}
+}
+
+// InjectedEnv returns local stand-ins for the vars Kyper injects (see
+// kyperfile.AutoInjectedEnv), limited to the deps kf declares.
+func InjectedEnv(kf *config.KyperFile, slug string) (map[string]string, error) {
+	secret := make([]byte, 64)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generating SECRET_KEY_BASE: %w", err)
+	}
+	env := map[string]string{
+		"PORT":                fmt.Sprintf("%d", ContainerPort),
+		"SECRET_KEY_BASE":     hex.EncodeToString(secret),
+		"KYPER_DEPLOYMENT_ID": "local",
+	}
+	for _, dep := range kf.Deps {
+		switch dep.Name {
+		case "postgres":
+			env["DATABASE_URL"] = fmt.Sprintf("postgres://%s:%s@postgres:5432/%s", dbUser, dbPassword, dbName(slug))
+		case "mysql":
+			env["DATABASE_URL"] = fmt.Sprintf("mysql://%s:%s@mysql:3306/%s", dbUser, dbPassword, dbName(slug))
+		case "redis":
+			env["REDIS_URL"] = "redis://redis:6379/0"
+		case "elasticsearch":
+			env["ELASTICSEARCH_URL"] = "http://elasticsearch:9200"
+		case "opensearch":
+			env["OPENSEARCH_URL"] = "http://opensearch:9200"
+		case "s3":
+			env["AWS_ACCESS_KEY_ID"] = s3AccessKey
+			env["AWS_SECRET_ACCESS_KEY"] = s3SecretKey
+			env["AWS_BUCKET"] = slug
+			env["AWS_ENDPOINT_URL"] = fmt.Sprintf("http://s3:%d", s3Port)
+		}
+	}
+	return env, nil
+}
+
+func dbName(slug string) string {
+	return strings.ReplaceAll(slug, "-", "_")
+}
+
+// escape protects '$' from compose's variable interpolation so commands
+// like "rails server -p $PORT" reach the container's shell intact.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeEnv(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		out[k] = escape(v)
+	}
+	return out
+}
+
+// command builds `<engine> compose` for this project.
+func (p *Project) command(args ...string) *exec.Cmd {
+	base := []string{"compose", "-p", p.Name, "-f", filepath.Join(p.Dir, "compose.yml")}
+	cmd := exec.Command(p.Engine, append(base, args...)...)
+	cmd.Stdout = p.Stdout
+	cmd.Stderr = p.Stderr
+	return cmd
+}
+
+// Up starts the given services (and the deps they need) in the background.
+func (p *Project) Up(services ...string) error {
+	args := append([]string{"up", "--detach", "--no-build"}, services...)
+	if err := p.command(args...).Run(); err != nil {
+		return fmt.Errorf("starting containers: %w", err)
+	}
+	return nil
+}
+
+// Run executes a shell command in a one-off container of service, starting
+// deps first. It returns the command's exit code.
+func (p *Project) Run(service, command string) (int, error) {
+	err := p.command("run", "--rm", "--no-TTY", service, "sh", "-c", command).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("running %s: %w", service, err)
+	}
+	return 0, nil
+}
+
+// Logs prints the services' logs, following them if follow is set. The
+// returned command has been started; Wait on it.
+func (p *Project) Logs(follow bool, services ...string) (*exec.Cmd, error) {
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	cmd := p.command(append(args, services...)...)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("reading logs: %w", err)
+	}
+	return cmd, nil
+}
+
+// Port returns the host address a service's container port is published on.
+func (p *Project) Port(service string, port int) (string, error) {
+	cmd := p.command("port", service, fmt.Sprintf("%d", port))
+	var out strings.Builder
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("looking up port: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// Running reports whether service has a running container.
+func (p *Project) Running(service string) (bool, error) {
+	cmd := p.command("ps", "--services", "--status", "running")
+	var out strings.Builder
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("checking containers: %w", err)
+	}
+	for _, name := range strings.Fields(out.String()) {
+		if name == service {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Close stops and removes the project's containers and volumes, then
+// deletes the generated files.
+func (p *Project) Close() error {
+	cmd := p.command("down", "--volumes", "--remove-orphans", "--timeout", "5")
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	err := cmd.Run()
+	_ = os.RemoveAll(p.Dir)
+	if err != nil {
+		return fmt.Errorf("removing containers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Package dotenv parses .env files: KEY=value lines with optional export
+// prefixes, single- and double-quoted (possibly multi-line) values, inline
+// comments and ${VAR} interpolation.
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var keyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ValidKey reports whether key is a usable environment variable name.
+func ValidKey(key string) bool {
+	return keyRegexp.MatchString(key)
+}
+
+// Lookup resolves variables referenced by ${VAR} that aren't defined earlier
+// in the same file. os.LookupEnv is the usual choice; nil disables it.
+type Lookup func(key string) (string, bool)
+
+// ReadFile parses the file at path. A missing file yields an empty map, so
+// callers can treat the .env file as optional.
+func ReadFile(path string, lookup Lookup) (map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	vars, err := Parse(f, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// Parse reads dotenv content from r. Later assignments win. Lines without an
+// '=' are ignored, matching the lenient behaviour of most dotenv loaders.
+func Parse(r io.Reader, lookup Lookup) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		src:    strings.ReplaceAll(string(data), "\r\n", "\n"),
+		line:   1,
+		vars:   map[string]string{},
+		lookup: lookup,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+type parser struct {
+	src    string
+	pos    int
+	line   int
+	vars   map[string]string
+	lookup Lookup
+}
+
+func (p *parser) parse() error {
+	for p.pos < len(p.src) {
+		start := p.line
+		raw := p.readLine()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !ValidKey(key) {
+			return fmt.Errorf("line %d: invalid variable name %q", start, key)
+		}
+
+		value = strings.TrimLeft(value, " \t")
+		var err error
+		switch {
+		case strings.HasPrefix(value, "'"):
+			value, err = p.quoted(value[1:], '\'', start)
+		case strings.HasPrefix(value, `"`):
+			value, err = p.quoted(value[1:], '"', start)
+			if err == nil {
+				value = p.expand(unescape(value))
+			}
+		default:
+			value = p.expand(stripComment(value))
+		}
+		if err != nil {
+			return err
+		}
+		p.vars[key] = value
+	}
+	return nil
+}
+
+// readLine returns the next line without its newline.
+func (p *parser) readLine() string {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		line := p.src[p.pos:]
+		p.pos = len(p.src)
+		return line
+	}
+	line := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	p.line++
+	return line
+}
+
+// quoted finds the closing quote for a value that starts on the current line
+// with rest, consuming further lines if the value spans them. Anything after
+// the closing quote other than a comment is an error.
+func (p *parser) quoted(rest string, quote byte, start int) (string, error) {
+	var b strings.Builder
+	for {
+		if i := closingQuote(rest, quote); i >= 0 {
+			b.WriteString(rest[:i])
+			trailing := strings.TrimSpace(rest[i+1:])
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return "", fmt.Errorf("line %d: unexpected %q after closing quote", start, trailing)
+			}
+			return b.String(), nil
+		}
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("line %d: unterminated %c-quoted value", start, quote)
+		}
+		b.WriteString(rest)
+		b.WriteByte('\n')
+		rest = p.readLine()
+	}
+}
+
+// closingQuote returns the index of the first unescaped quote in s, or -1.
+// Backslashes only escape inside double quotes.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment drops a trailing " # comment" from an unquoted value.
+func stripComment(v string) string {
+	for i := 1; i < len(v); i++ {
+		if v[i] == '#' && (v[i-1] == ' ' || v[i-1] == '\t') {
+			v = v[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(v)
+}
+
+func unescape(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i == len(v)-1 {
+			b.WriteByte(v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '$':
+			// Keep the escape so expand leaves the dollar sign alone.
+			b.WriteString(`\$`)
+		default:
+			b.WriteByte(v[i])
+		}
+	}
+	return b.String()
+}
+
+// expand substitutes ${VAR}, ${VAR:-default} and $VAR. Variables defined
+// earlier in the file take precedence over the lookup function; unknown
+// variables expand to the empty string.
+func (p *parser) expand(v string) string {
+	if !strings.Contains(v, "$") {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c == '\\' && i+1 < len(v) && v[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 == len(v) {
+			b.WriteByte(c)
+			continue
+		}
+
+		if v[i+1] == '{' {
+			end := strings.IndexByte(v[i+2:], '}')
+			if end < 0 {
+				b.WriteString(v[i:])
+				break
+			}
+			expr := v[i+2 : i+2+end]
+			name, def, hasDef := strings.Cut(expr, ":-")
+			if val, ok := p.resolve(name); ok && val != "" {
+				b.WriteString(val)
+			} else if hasDef {
+				b.WriteString(def)
+			}
+			i += end + 2
+			continue
+		}
+
+		j := i + 1
+		for j < len(v) && (v[j] == '_' || isAlnum(v[j])) {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(c)
+			continue
+		}
+		val, _ := p.resolve(v[i+1 : j])
+		b.WriteString(val)
+		i = j - 1
+	}
+	return b.String()
+}
+
+func (p *parser) resolve(name string) (string, bool) {
+	if val, ok := p.vars[name]; ok {
+		return val, true
+	}
+	if p.lookup != nil {
+		return p.lookup(name)
+	}
+	return "", false
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// ParseAssignment splits a single KEY=VAL pair as given on the command line.
+// The value is taken literally.
+func ParseAssignment(s string) (string, string, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("%q is not in KEY=VALUE form", s)
+	}
+	if !ValidKey(key) {
+		return "", "", fmt.Errorf("invalid variable name %q", key)
+	}
+	return key, value, nil
+}
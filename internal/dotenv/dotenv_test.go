@@ -0,0 +1,104 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		if key == "HOME" {
+			return "/home/dev", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{"plain", "MY_KEY=hello\nOTHER_KEY=world\n", map[string]string{"MY_KEY": "hello", "OTHER_KEY": "world"}},
+		{"comments and blank lines", "# comment\n\nMY_KEY=hello\n  # indented\n", map[string]string{"MY_KEY": "hello"}},
+		{"lines without = are skipped", "NOEQUALS\nMY_KEY=hello\n", map[string]string{"MY_KEY": "hello"}},
+		{"= in value", "MY_KEY=val=ue", map[string]string{"MY_KEY": "val=ue"}},
+		{"export prefix", "export TOKEN=abc\nexporter=1", map[string]string{"TOKEN": "abc", "exporter": "1"}},
+		{"spaces around =", "KEY = value ", map[string]string{"KEY": "value"}},
+		{"inline comment", "KEY=value # note\nHASH=a#b", map[string]string{"KEY": "value", "HASH": "a#b"}},
+		{"empty value", "KEY=\nOTHER=''", map[string]string{"KEY": "", "OTHER": ""}},
+		{"single quotes are literal", `KEY='a # b $HOME \n'`, map[string]string{"KEY": `a # b $HOME \n`}},
+		{"double quote escapes", `KEY="line1\nline2 \"q\" \$HOME" # c`, map[string]string{"KEY": "line1\nline2 \"q\" $HOME"}},
+		{"multi-line double quotes", "CERT=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT=1", map[string]string{"CERT": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"}},
+		{"multi-line single quotes", "KEY='a\nb'", map[string]string{"KEY": "a\nb"}},
+		{"interpolation from file", "HOST=db\nURL=postgres://${HOST}:5432/$HOST", map[string]string{"HOST": "db", "URL": "postgres://db:5432/db"}},
+		{"interpolation from lookup", `DIR="${HOME}/app"`, map[string]string{"DIR": "/home/dev/app"}},
+		{"interpolation default", "A=${MISSING:-fallback}\nB=${MISSING}x", map[string]string{"A": "fallback", "B": "x"}},
+		{"crlf line endings", "A=1\r\nB=\"2\"\r\n", map[string]string{"A": "1", "B": "2"}},
+		{"later wins", "A=1\nA=2", map[string]string{"A": "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.in), lookup)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d vars %v, want %v", len(got), got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"unterminated quote", "A=1\nKEY=\"abc\nmore", `line 2: unterminated "-quoted value`},
+		{"invalid name", "MY KEY=1", `line 1: invalid variable name "MY KEY"`},
+		{"junk after quote", `KEY="a" b`, `unexpected "b" after closing quote`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.in), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	got, err := ReadFile("/nonexistent/.env", nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("missing file: got %v, %v; want empty map", got, err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	_ = os.WriteFile(path, []byte("KEY='oops\n"), 0644)
+	if _, err := ReadFile(path, nil); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected error naming the file, got %v", err)
+	}
+}
+
+func TestParseAssignment(t *testing.T) {
+	k, v, err := ParseAssignment("STRIPE_KEY=sk_test=1 # literal")
+	if err != nil || k != "STRIPE_KEY" || v != "sk_test=1 # literal" {
+		t.Errorf("got %q %q %v", k, v, err)
+	}
+	if _, _, err := ParseAssignment("NOVALUE"); err == nil {
+		t.Error("expected error for missing =")
+	}
+	if _, _, err := ParseAssignment("1BAD=x"); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
@@ -0,0 +1,360 @@
+package kyperfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+// Instruction is one Dockerfile instruction with its continuation lines
+// joined.
+type Instruction struct {
+	// Cmd is the upper-cased instruction keyword, e.g. "FROM".
+	Cmd  string
+	Args string
+	// Line is the 1-based line the instruction starts on.
+	Line int
+}
+
+// ParseDockerfile splits a Dockerfile into instructions. It understands
+// comments, the escape parser directive and line continuations, which is
+// enough for linting; it doesn't expand variables or parse heredocs.
+func ParseDockerfile(r io.Reader) ([]Instruction, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	escape := `\`
+	var instrs []Instruction
+	var cur strings.Builder
+	start, lineNo := 0, 0
+	directives := true
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if directives {
+			if m := escapeDirective.FindStringSubmatch(trimmed); m != nil {
+				escape = m[1]
+				continue
+			}
+			if !strings.HasPrefix(trimmed, "#") || trimmed == "" {
+				directives = false
+			}
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if cur.Len() == 0 {
+			start = lineNo
+		} else {
+			cur.WriteByte(' ')
+		}
+		if strings.HasSuffix(trimmed, escape) {
+			cur.WriteString(strings.TrimSpace(strings.TrimSuffix(trimmed, escape)))
+			continue
+		}
+		cur.WriteString(trimmed)
+		instrs = append(instrs, newInstruction(cur.String(), start))
+		cur.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if cur.Len() > 0 {
+		instrs = append(instrs, newInstruction(cur.String(), start))
+	}
+	return instrs, nil
+}
+
+var escapeDirective = regexp.MustCompile("(?i)^#\\s*escape\\s*=\\s*([\\\\`])$")
+
+func newInstruction(s string, line int) Instruction {
+	cmd, args, _ := strings.Cut(s, " ")
+	return Instruction{Cmd: strings.ToUpper(cmd), Args: strings.TrimSpace(args), Line: line}
+}
+
+// LintDockerfile parses kf's Dockerfile and adds Kyper-specific findings to
+// r: how the image listens, which user it runs as, reproducibility of base
+// images and downloads, what ends up in the image, and whether its CMD
+// matches processes.web. It does nothing if the Dockerfile doesn't exist;
+// Validate reports that.
+func LintDockerfile(kf *config.KyperFile, r *ValidationResult) {
+	if kf.Docker.Dockerfile == "" {
+		return
+	}
+	path := kf.DockerfilePath()
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	instrs, err := ParseDockerfile(f)
+	if err != nil {
+		addError(r, fmt.Sprintf("reading %s: %v", kf.Docker.Dockerfile, err))
+		return
+	}
+	lintInstructions(kf, filepath.Clean(kf.Docker.Dockerfile), instrs, hasDockerignore(kf), r)
+}
+
+// hasDockerignore reports whether the build will see a .dockerignore: one
+// at the context root, or a <Dockerfile>.dockerignore next to the Dockerfile.
+func hasDockerignore(kf *config.KyperFile) bool {
+	for _, p := range []string{
+		filepath.Join(kf.ContextDir(), ".dockerignore"),
+		kf.DockerfilePath() + ".dockerignore",
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func lintInstructions(kf *config.KyperFile, name string, instrs []Instruction, dockerignore bool, r *ValidationResult) {
+	at := func(in Instruction) string { return fmt.Sprintf("%s:%d", name, in.Line) }
+
+	// Global ARG defaults can be used in FROM lines.
+	args := map[string]string{}
+	stages := map[string]bool{}
+	var final []Instruction
+	sawFrom := false
+	for _, in := range instrs {
+		switch in.Cmd {
+		case "ARG":
+			if !sawFrom {
+				k, v, _ := strings.Cut(in.Args, "=")
+				args[k] = strings.Trim(v, `"'`)
+			}
+		case "FROM":
+			sawFrom = true
+			final = nil
+			image, alias := parseFrom(in.Args)
+			lintBaseImage(expandArgs(image, args), stages, at(in), r)
+			if alias != "" {
+				stages[strings.ToLower(alias)] = true
+			}
+		case "ADD":
+			lintAdd(in, at(in), r)
+		case "COPY":
+			if !dockerignore && copiesWholeContext(in.Args) {
+				addWarning(r, fmt.Sprintf("%s: COPY %s copies the whole build context but there is no .dockerignore — .git, .env and local dependencies end up in the image", at(in), in.Args))
+			}
+		}
+		final = append(final, in)
+	}
+	if !sawFrom {
+		addError(r, fmt.Sprintf("%s: no FROM instruction", name))
+		return
+	}
+
+	lintFinalStage(kf, name, final, at, r)
+}
+
+// parseFrom returns the image and stage alias of a FROM instruction's
+// arguments, skipping flags such as --platform.
+func parseFrom(s string) (image, alias string) {
+	var fields []string
+	for _, f := range strings.Fields(s) {
+		if !strings.HasPrefix(f, "--") {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "as") {
+		alias = fields[2]
+	}
+	return fields[0], alias
+}
+
+var argRef = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+
+// expandArgs substitutes known ARG defaults into s, leaving unknown
+// references in place.
+func expandArgs(s string, args map[string]string) string {
+	return argRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if v, ok := args[argRef.FindStringSubmatch(ref)[1]]; ok && v != "" {
+			return v
+		}
+		return ref
+	})
+}
+
+func lintBaseImage(image string, stages map[string]bool, at string, r *ValidationResult) {
+	if image == "" || strings.EqualFold(image, "scratch") || stages[strings.ToLower(image)] ||
+		strings.Contains(image, "$") || strings.Contains(image, "@") {
+		return
+	}
+	// A colon after the last slash separates the tag; one before it is a
+	// registry port.
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	switch tag {
+	case "":
+		addWarning(r, fmt.Sprintf("%s: base image %s has no tag, so it tracks latest — pin a version for reproducible builds", at, image))
+	case "latest":
+		addWarning(r, fmt.Sprintf("%s: base image %s uses the latest tag — pin a version for reproducible builds", at, image))
+	}
+}
+
+func lintAdd(in Instruction, at string, r *ValidationResult) {
+	if strings.Contains(in.Args, "--checksum=") {
+		return
+	}
+	for _, f := range strings.Fields(in.Args) {
+		lower := strings.ToLower(f)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+			addWarning(r, fmt.Sprintf("%s: ADD of remote URL %s isn't verified or cached reliably — use ADD --checksum=sha256:... or download with curl and check the hash", at, f))
+			return
+		}
+	}
+}
+
+// copiesWholeContext reports whether COPY arguments include the context
+// root as a source. Copies from other stages don't read the context.
+func copiesWholeContext(s string) bool {
+	fields := instructionArgs(s)
+	var srcs []string
+	for _, f := range fields {
+		if strings.HasPrefix(f, "--from") {
+			return false
+		}
+		if !strings.HasPrefix(f, "--") {
+			srcs = append(srcs, f)
+		}
+	}
+	if len(srcs) < 2 {
+		return false
+	}
+	for _, src := range srcs[:len(srcs)-1] {
+		if src == "." || src == "./" {
+			return true
+		}
+	}
+	return false
+}
+
+func lintFinalStage(kf *config.KyperFile, name string, final []Instruction, at func(Instruction) string, r *ValidationResult) {
+	var expose, user, cmd, entrypoint *Instruction
+	envPort := ""
+	for i := range final {
+		in := &final[i]
+		switch in.Cmd {
+		case "ENV":
+			if v, ok := envValue(in.Args, "PORT"); ok {
+				envPort = v
+			}
+		case "EXPOSE":
+			expose = in
+		case "USER":
+			user = in
+		case "CMD":
+			cmd = in
+		case "ENTRYPOINT":
+			entrypoint = in
+		}
+	}
+
+	switch {
+	case expose == nil:
+		addWarning(r, fmt.Sprintf("%s: no EXPOSE — Kyper sends traffic to $PORT; add EXPOSE $PORT and listen on it", name))
+	case !exposesPort(expose.Args, envPort):
+		addWarning(r, fmt.Sprintf("%s: EXPOSE %s ignores $PORT — Kyper sends traffic to $PORT, so listen on it and EXPOSE $PORT", at(*expose), expose.Args))
+	}
+
+	if user == nil {
+		addWarning(r, fmt.Sprintf("%s: the image runs as root — add a USER instruction with a non-root user", name))
+	} else if isRootUser(user.Args) {
+		addWarning(r, fmt.Sprintf("%s: USER %s runs the app as root — switch to a non-root user", at(*user), user.Args))
+	}
+
+	if entrypoint != nil && len(kf.Processes) > 0 {
+		addWarning(r, fmt.Sprintf("%s: ENTRYPOINT %s wraps every process — Kyper passes processes from kyper.yml to it as arguments, so make sure it execs them", at(*entrypoint), entrypoint.Args))
+	}
+	if web, ok := kf.Processes["web"]; ok && cmd != nil {
+		if got := commandLine(cmd.Args); got != normalizeSpace(web) {
+			addWarning(r, fmt.Sprintf("%s: CMD %q differs from processes.web %q — Kyper runs processes.web, so the image behaves differently when run on its own", at(*cmd), got, normalizeSpace(web)))
+		}
+	}
+}
+
+// exposesPort reports whether EXPOSE arguments reference $PORT, or the
+// port the Dockerfile defaults PORT to.
+func exposesPort(s, envPort string) bool {
+	if strings.Contains(s, "PORT") {
+		return true
+	}
+	for _, f := range strings.Fields(s) {
+		port, _, _ := strings.Cut(f, "/")
+		if envPort != "" && port == envPort {
+			return true
+		}
+	}
+	return false
+}
+
+// envValue returns the value an ENV instruction gives key, in either the
+// "ENV KEY=value ..." or legacy "ENV KEY value" form.
+func envValue(s, key string) (string, bool) {
+	if k, v, ok := strings.Cut(s, " "); ok && !strings.Contains(k, "=") {
+		return strings.TrimSpace(v), k == key
+	}
+	for _, f := range strings.Fields(s) {
+		if k, v, ok := strings.Cut(f, "="); ok && k == key {
+			return strings.Trim(v, `"'`), true
+		}
+	}
+	return "", false
+}
+
+func isRootUser(s string) bool {
+	user, _, _ := strings.Cut(strings.TrimSpace(s), ":")
+	return user == "root" || user == "0"
+}
+
+// commandLine turns a CMD's exec or shell form into one command line,
+// unwrapping an explicit `sh -c`.
+func commandLine(s string) string {
+	var argv []string
+	if strings.HasPrefix(s, "[") && json.Unmarshal([]byte(s), &argv) == nil {
+		if len(argv) == 3 && (argv[0] == "sh" || argv[0] == "/bin/sh" || argv[0] == "bash" || argv[0] == "/bin/bash") && argv[1] == "-c" {
+			return normalizeSpace(argv[2])
+		}
+		return normalizeSpace(strings.Join(argv, " "))
+	}
+	return normalizeSpace(s)
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// instructionArgs returns the arguments of a COPY/ADD-style instruction in
+// either exec (JSON array) or shell form.
+func instructionArgs(s string) []string {
+	var flags []string
+	rest := s
+	for strings.HasPrefix(rest, "--") {
+		flag, after, _ := strings.Cut(rest, " ")
+		flags = append(flags, flag)
+		rest = strings.TrimSpace(after)
+	}
+	var argv []string
+	if strings.HasPrefix(rest, "[") && json.Unmarshal([]byte(rest), &argv) == nil {
+		return append(flags, argv...)
+	}
+	return append(flags, strings.Fields(rest)...)
+}
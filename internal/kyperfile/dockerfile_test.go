@@ -0,0 +1,140 @@
+package kyperfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestParseDockerfile(t *testing.T) {
+	src := "# syntax=docker/dockerfile:1\n" +
+		"FROM ruby:3.3-slim AS build\n" +
+		"\n" +
+		"RUN apt-get update && \\\n" +
+		"    # comments inside continuations are dropped\n" +
+		"    apt-get install -y build-essential\r\n" +
+		"cmd [\"bin/rails\", \"server\"]\n"
+	instrs, err := ParseDockerfile(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseDockerfile failed: %v", err)
+	}
+	if len(instrs) != 3 {
+		t.Fatalf("got %d instructions: %+v", len(instrs), instrs)
+	}
+	if instrs[1].Cmd != "RUN" || instrs[1].Line != 4 || instrs[1].Args != "apt-get update && apt-get install -y build-essential" {
+		t.Errorf("continuation not joined: %+v", instrs[1])
+	}
+	if instrs[2].Cmd != "CMD" || instrs[2].Line != 7 {
+		t.Errorf("keyword not upper-cased: %+v", instrs[2])
+	}
+
+	instrs, err = ParseDockerfile(strings.NewReader("# escape=`\nFROM alpine:3.20\nRUN echo a `\n  b\n"))
+	if err != nil {
+		t.Fatalf("ParseDockerfile failed: %v", err)
+	}
+	if len(instrs) != 2 || instrs[1].Args != "echo a b" {
+		t.Errorf("escape directive not honoured: %+v", instrs)
+	}
+}
+
+func lint(t *testing.T, dockerfile string, dockerignore bool) *ValidationResult {
+	t.Helper()
+	instrs, err := ParseDockerfile(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatalf("ParseDockerfile failed: %v", err)
+	}
+	kf := validKyperFile()
+	kf.Processes = map[string]string{"web": "bin/rails server -p $PORT"}
+	r := &ValidationResult{Valid: true}
+	lintInstructions(kf, "Dockerfile", instrs, dockerignore, r)
+	return r
+}
+
+func TestLintDockerfileClean(t *testing.T) {
+	r := lint(t, `ARG RUBY_VERSION=3.3.5
+FROM ruby:${RUBY_VERSION}-slim AS build
+COPY . .
+RUN bundle install
+
+FROM ruby:3.3.5-slim
+COPY --from=build /app /app
+ENV PORT=3000
+EXPOSE 3000
+USER app
+CMD bin/rails server -p $PORT
+`, true)
+	if !r.Valid || len(r.Warnings) > 0 {
+		t.Errorf("expected no findings, got errors %v warnings %v", r.Errors, r.Warnings)
+	}
+}
+
+func TestLintDockerfileFindings(t *testing.T) {
+	r := lint(t, `FROM node:latest AS assets
+RUN npm ci
+
+FROM ruby
+ADD https://example.com/tool.tar.gz /tmp/
+ADD --checksum=sha256:abc https://example.com/ok.tar.gz /tmp/
+COPY . /app
+EXPOSE 3000
+USER root
+ENTRYPOINT ["./entrypoint.sh"]
+CMD ["bundle", "exec", "puma"]
+`, false)
+	if !r.Valid {
+		t.Errorf("lint findings should be warnings, got errors %v", r.Errors)
+	}
+	for _, want := range []string{
+		"Dockerfile:1: base image node:latest uses the latest tag",
+		"Dockerfile:4: base image ruby has no tag",
+		"Dockerfile:5: ADD of remote URL https://example.com/tool.tar.gz",
+		"Dockerfile:7: COPY . /app copies the whole build context but there is no .dockerignore",
+		"Dockerfile:8: EXPOSE 3000 ignores $PORT",
+		"Dockerfile:9: USER root runs the app as root",
+		"Dockerfile:10: ENTRYPOINT",
+		`Dockerfile:11: CMD "bundle exec puma" differs from processes.web "bin/rails server -p $PORT"`,
+	} {
+		assertContainsWarning(t, r, want)
+	}
+	if len(r.Warnings) != 8 {
+		t.Errorf("expected 8 warnings, got %d: %v", len(r.Warnings), r.Warnings)
+	}
+}
+
+func TestLintDockerfileMissingInstructions(t *testing.T) {
+	r := lint(t, "FROM ruby:3.3.5-slim\nCMD [\"sh\", \"-c\", \"bin/rails server -p $PORT\"]\n", true)
+	assertContainsWarning(t, r, "Dockerfile: no EXPOSE")
+	assertContainsWarning(t, r, "Dockerfile: the image runs as root")
+	if len(r.Warnings) != 2 {
+		t.Errorf("sh -c CMD matching processes.web should not warn: %v", r.Warnings)
+	}
+
+	r = lint(t, "RUN echo hi\n", true)
+	assertContainsError(t, r, "Dockerfile: no FROM instruction")
+}
+
+func TestLintDockerfileReadsDockerignore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM ruby:3.3.5\nCOPY . .\nEXPOSE $PORT\nUSER app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kf := validKyperFile()
+	kf.Dir = dir
+	kf.Docker = config.DockerConfig{Dockerfile: "./Dockerfile"}
+
+	r := &ValidationResult{Valid: true}
+	LintDockerfile(kf, r)
+	assertContainsWarning(t, r, "Dockerfile:2: COPY . . copies the whole build context")
+
+	if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(".git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r = &ValidationResult{Valid: true}
+	LintDockerfile(kf, r)
+	if len(r.Warnings) != 0 {
+		t.Errorf("expected no warnings with a .dockerignore, got %v", r.Warnings)
+	}
+}
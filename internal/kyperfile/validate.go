@@ -78,9 +78,11 @@ func Validate(kf *config.KyperFile, checkFileExists bool) *ValidationResult {
 	validateDescription(kf, r)
 	validateTagline(kf, r)
 	validateDocker(kf, r, checkFileExists)
+	validateBuild(kf, r, checkFileExists)
 	validateProcesses(kf, r)
 	validateDeps(kf, r)
 	validateHealthcheck(kf, r)
+	validateSmoke(kf, r)
 	validatePricing(kf, r)
 	validateEnv(kf, r)
 	checkDBWithoutHook(kf, r)
@@ -158,12 +160,57 @@ func validateDocker(kf *config.KyperFile, r *ValidationResult, checkFileExists b
 		return
 	}
 	if checkFileExists {
-		if _, err := os.Stat(kf.Docker.Dockerfile); os.IsNotExist(err) {
+		if _, err := os.Stat(kf.DockerfilePath()); os.IsNotExist(err) {
 			addError(r, fmt.Sprintf("docker.dockerfile %q not found", kf.Docker.Dockerfile))
 		}
 	}
 }
 
+func validateBuild(kf *config.KyperFile, r *ValidationResult, checkFileExists bool) {
+	if kf.Build.Context != "" && checkFileExists {
+		info, err := os.Stat(kf.ContextDir())
+		if err != nil || !info.IsDir() {
+			addError(r, fmt.Sprintf("build.context %q is not a directory", kf.Build.Context))
+			return
+		}
+	}
+	if kf.Docker.Dockerfile != "" {
+		if _, err := kf.DockerfileInContext(); err != nil {
+			addError(r, err.Error())
+		}
+	}
+
+	if p := kf.Build.Platform; p != "" {
+		if !platformRegexp.MatchString(p) {
+			addError(r, fmt.Sprintf("build.platform %q must be a linux platform such as linux/amd64", p))
+		} else if p != KyperPlatform {
+			addWarning(r, fmt.Sprintf("build.platform %q differs from Kyper's builders (%s) — the image you test locally won't be the one that ships", p, KyperPlatform))
+		}
+	}
+	for k := range kf.Build.Args {
+		if !envNameRegexp.MatchString(k) {
+			addError(r, fmt.Sprintf("build.args: %q is not a valid build arg name", k))
+		}
+	}
+	for i, spec := range kf.Build.Secrets {
+		if _, err := config.SecretID(spec); err != nil {
+			addError(r, fmt.Sprintf("build.secrets[%d]: %s", i, err))
+		}
+	}
+	for field, specs := range map[string][]string{"cache_from": kf.Build.CacheFrom, "cache_to": kf.Build.CacheTo} {
+		for i, spec := range specs {
+			if strings.TrimSpace(spec) == "" || (strings.Contains(spec, "=") && !strings.Contains(spec, "type=")) {
+				addError(r, fmt.Sprintf("build.%s[%d]: %q must be an image ref or a cache spec with type=", field, i, spec))
+			}
+		}
+	}
+}
+
+// KyperPlatform is the platform Kyper builds and runs images on.
+const KyperPlatform = "linux/amd64"
+
+var platformRegexp = regexp.MustCompile(`^linux/[a-z0-9]+(/v[0-9]+)?$`)
+
 func validateProcesses(kf *config.KyperFile, r *ValidationResult) {
 	if len(kf.Processes) == 0 {
 		addError(r, "processes is required")
@@ -233,6 +280,29 @@ func validateHealthcheck(kf *config.KyperFile, r *ValidationResult) {
 	}
 }
 
+func validateSmoke(kf *config.KyperFile, r *ValidationResult) {
+	for i, c := range kf.Smoke {
+		label := fmt.Sprintf("smoke[%d]", i)
+		if c.Name != "" {
+			label = fmt.Sprintf("smoke %q", c.Name)
+		}
+		if !strings.HasPrefix(c.Path, "/") {
+			addError(r, label+": path must start with /")
+		}
+		if c.Status != 0 && (c.Status < 100 || c.Status > 599) {
+			addError(r, label+": status must be a valid HTTP status code")
+		}
+		if c.Body != "" {
+			if _, err := regexp.Compile(c.Body); err != nil {
+				addError(r, fmt.Sprintf("%s: body is not a valid regular expression: %v", label, err))
+			}
+		}
+		if c.Timeout < 0 {
+			addError(r, label+": timeout must be a positive integer")
+		}
+	}
+}
+
 func validatePricing(kf *config.KyperFile, r *ValidationResult) {
 	if kf.Pricing.OneTime == nil && kf.Pricing.Subscription == nil {
 		addError(r, "at least one pricing option is required (one_time or subscription)")
@@ -247,18 +317,100 @@ func validatePricing(kf *config.KyperFile, r *ValidationResult) {
 }
 
 func validateEnv(kf *config.KyperFile, r *ValidationResult) {
+	autoInjected := make(map[string]bool)
+	for _, e := range AutoInjectedEnv {
+		autoInjected[e] = true
+	}
+	seen := make(map[string]bool)
+	for i, e := range kf.Env {
+		if e.Name == "" {
+			addError(r, fmt.Sprintf("env[%d]: env entries must be non-empty strings or mappings with a name", i))
+			continue
+		}
+		label := fmt.Sprintf("env %q", e.Name)
+		// Name problems are warnings: before the mapping form existed any
+		// non-empty name was accepted, and those files must keep passing.
+		if seen[e.Name] {
+			addWarning(r, label+": declared more than once")
+		}
+		seen[e.Name] = true
+		if autoInjected[e.Name] {
+			addWarning(r, fmt.Sprintf("env %q is auto-injected by Kyper and cannot be overridden", e.Name))
+		}
+		if !envNameRegexp.MatchString(e.Name) {
+			addWarning(r, label+": name should contain only letters, digits and underscores and not start with a digit")
+		}
+
+		if e.Pattern != "" && len(e.Enum) > 0 {
+			addError(r, label+": set either pattern or enum, not both")
+		}
+		if e.Pattern != "" {
+			if _, err := regexp.Compile(e.Pattern); err != nil {
+				addError(r, fmt.Sprintf("%s: pattern is not a valid regular expression: %v", label, err))
+				continue
+			}
+		}
+		if e.Default != "" {
+			if err := CheckEnvValue(e, e.Default); err != nil {
+				addError(r, fmt.Sprintf("%s: default %v", label, err))
+			}
+			if e.Secret {
+				addWarning(r, label+": secrets shouldn't have a default — it is visible to anyone who reads kyper.yml")
+			}
+		}
+	}
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CheckEnvValue checks a value against an entry's pattern or enum. The error
+// never includes the value, since it may be a secret.
+func CheckEnvValue(e config.EnvEntry, value string) error {
+	if e.Pattern != "" {
+		re, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return fmt.Errorf("cannot be checked: invalid pattern: %w", err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("does not match pattern %q", e.Pattern)
+		}
+	}
+	if len(e.Enum) > 0 {
+		for _, allowed := range e.Enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of: %s", strings.Join(e.Enum, ", "))
+	}
+	return nil
+}
+
+// CheckEnvValues validates a set of env values against kyper.yml's env
+// schema: invalid values are errors, and required vars with neither a value
+// nor a default are warnings. Auto-injected vars are skipped.
+func CheckEnvValues(kf *config.KyperFile, vars map[string]string) *ValidationResult {
+	r := &ValidationResult{Valid: true}
 	autoInjected := make(map[string]bool)
 	for _, e := range AutoInjectedEnv {
 		autoInjected[e] = true
 	}
 	for _, e := range kf.Env {
-		if e == "" {
-			addError(r, "env entries must be non-empty strings")
+		if autoInjected[e.Name] {
+			continue
 		}
-		if autoInjected[e] {
-			addWarning(r, fmt.Sprintf("env %q is auto-injected by Kyper and cannot be overridden", e))
+		value, ok := vars[e.Name]
+		if !ok {
+			if e.IsRequired() && e.Default == "" {
+				addWarning(r, fmt.Sprintf("%s is required but not set", e.Name))
+			}
+			continue
+		}
+		if err := CheckEnvValue(e, value); err != nil {
+			addError(r, fmt.Sprintf("%s %v", e.Name, err))
 		}
 	}
+	return r
 }
 
 func checkDBWithoutHook(kf *config.KyperFile, r *ValidationResult) {
@@ -1,6 +1,8 @@
 package kyperfile
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -308,14 +310,14 @@ func TestPricingValid(t *testing.T) {
 
 func TestEnvNonEmpty(t *testing.T) {
 	kf := validKyperFile()
-	kf.Env = []string{"API_KEY", ""}
+	kf.Env = []config.EnvEntry{{Name: "API_KEY"}, {Name: ""}}
 	r := Validate(kf, false)
 	assertContainsError(t, r, "env entries must be non-empty strings")
 }
 
 func TestEnvAutoInjectedWarning(t *testing.T) {
 	kf := validKyperFile()
-	kf.Env = []string{"DATABASE_URL"}
+	kf.Env = []config.EnvEntry{{Name: "DATABASE_URL"}}
 	r := Validate(kf, false)
 	if !r.Valid {
 		t.Errorf("auto-injected env should be a warning, not error")
@@ -323,6 +325,85 @@ func TestEnvAutoInjectedWarning(t *testing.T) {
 	assertContainsWarning(t, r, "auto-injected")
 }
 
+func TestEnvSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   config.EnvEntry
+		wantErr string
+	}{
+		{"valid pattern", config.EnvEntry{Name: "STRIPE_KEY", Pattern: "^sk_", Secret: true}, ""},
+		{"valid enum with default", config.EnvEntry{Name: "LOG_LEVEL", Enum: []string{"debug", "info"}, Default: "info"}, ""},
+		{"bad pattern", config.EnvEntry{Name: "A", Pattern: "("}, "pattern is not a valid regular expression"},
+		{"pattern and enum", config.EnvEntry{Name: "A", Pattern: ".", Enum: []string{"x"}}, "set either pattern or enum"},
+		{"default fails enum", config.EnvEntry{Name: "A", Enum: []string{"x", "y"}, Default: "z"}, "default must be one of: x, y"},
+		{"default fails pattern", config.EnvEntry{Name: "A", Pattern: "^[0-9]+$", Default: "abc"}, "default does not match pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kf := validKyperFile()
+			kf.Env = []config.EnvEntry{tt.entry}
+			r := Validate(kf, false)
+			if tt.wantErr == "" {
+				if !r.Valid {
+					t.Errorf("expected valid, got errors: %v", r.Errors)
+				}
+				return
+			}
+			assertContainsError(t, r, tt.wantErr)
+		})
+	}
+}
+
+func TestEnvDuplicateAndSecretDefault(t *testing.T) {
+	kf := validKyperFile()
+	kf.Env = []config.EnvEntry{{Name: "A"}, {Name: "A"}, {Name: "TOKEN", Secret: true, Default: "changeme"}}
+	r := Validate(kf, false)
+	assertContainsWarning(t, r, `env "A": declared more than once`)
+	assertContainsWarning(t, r, "secrets shouldn't have a default")
+}
+
+func TestEnvNameIsOnlyAWarning(t *testing.T) {
+	kf := validKyperFile()
+	kf.Env = []config.EnvEntry{{Name: "STRIPE-KEY"}}
+	r := Validate(kf, false)
+	if !r.Valid {
+		t.Errorf("a plain env name must not fail validation, got errors: %v", r.Errors)
+	}
+	assertContainsWarning(t, r, "name should contain only letters")
+}
+
+func TestCheckEnvValues(t *testing.T) {
+	optional := false
+	kf := &config.KyperFile{Env: []config.EnvEntry{
+		{Name: "STRIPE_KEY", Pattern: "^sk_(test|live)_", Secret: true},
+		{Name: "LOG_LEVEL", Enum: []string{"debug", "info"}},
+		{Name: "SMTP_URL"},
+		{Name: "SENTRY_DSN", Required: &optional},
+		{Name: "REGION", Default: "us"},
+		{Name: "DATABASE_URL"},
+	}}
+
+	r := CheckEnvValues(kf, map[string]string{"STRIPE_KEY": "pk_live_abc", "LOG_LEVEL": "trace"})
+	if len(r.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", r.Errors)
+	}
+	assertContainsError(t, r, `STRIPE_KEY does not match pattern "^sk_(test|live)_"`)
+	assertContainsError(t, r, "LOG_LEVEL must be one of: debug, info")
+	for _, e := range r.Errors {
+		if strings.Contains(e, "pk_live_abc") || strings.Contains(e, "trace") {
+			t.Errorf("error leaks the value: %s", e)
+		}
+	}
+	if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "SMTP_URL is required") {
+		t.Errorf("expected only SMTP_URL to be reported missing, got %v", r.Warnings)
+	}
+
+	r = CheckEnvValues(kf, map[string]string{"STRIPE_KEY": "sk_test_abc", "LOG_LEVEL": "info", "SMTP_URL": "smtp://x"})
+	if !r.Valid || len(r.Warnings) != 0 {
+		t.Errorf("expected clean result, got %+v", r)
+	}
+}
+
 func TestDepsS3Valid(t *testing.T) {
 	kf := validKyperFile()
 	kf.Deps = []config.DepEntry{{Name: "s3"}}
@@ -368,7 +449,7 @@ func TestDepsS3StorageGBTenNoWarn(t *testing.T) {
 
 func TestEnvAWSVarsAutoInjectedWarning(t *testing.T) {
 	kf := validKyperFile()
-	kf.Env = []string{"AWS_ACCESS_KEY_ID"}
+	kf.Env = []config.EnvEntry{{Name: "AWS_ACCESS_KEY_ID"}}
 	r := Validate(kf, false)
 	if !r.Valid {
 		t.Errorf("auto-injected AWS env should be a warning, not error")
@@ -427,3 +508,86 @@ func assertContainsWarning(t *testing.T, r *ValidationResult, substr string) {
 	}
 	t.Errorf("expected warning containing %q, got: %v", substr, r.Warnings)
 }
+
+func TestBuildContextMustExist(t *testing.T) {
+	kf := validKyperFile()
+	kf.Dir = t.TempDir()
+	kf.Build.Context = "missing"
+	r := Validate(kf, true)
+	assertContainsError(t, r, `build.context "missing" is not a directory`)
+}
+
+func TestDockerfileOutsideBuildContext(t *testing.T) {
+	kf := validKyperFile()
+	kf.Docker.Dockerfile = "../Dockerfile"
+	r := Validate(kf, false)
+	assertContainsError(t, r, "outside the build context")
+}
+
+func TestBuildDefaults(t *testing.T) {
+	kf := validKyperFile()
+	kf.Build = config.BuildConfig{
+		Platform:  "linux/arm64",
+		Args:      map[string]string{"RUBY_VERSION": "3.3.5"},
+		Secrets:   []string{"id=npmrc,src=.npmrc"},
+		CacheFrom: []string{"ghcr.io/acme/app:cache"},
+		CacheTo:   []string{"type=local,dest=.cache"},
+	}
+	r := Validate(kf, false)
+	if !r.Valid {
+		t.Errorf("expected valid, got errors: %v", r.Errors)
+	}
+	assertContainsWarning(t, r, `build.platform "linux/arm64" differs from Kyper's builders`)
+
+	kf.Build = config.BuildConfig{
+		Platform: "amd64",
+		Args:     map[string]string{"BAD-NAME": "x"},
+		Secrets:  []string{"src=.npmrc"},
+		CacheTo:  []string{"dest=.cache"},
+	}
+	r = Validate(kf, false)
+	assertContainsError(t, r, `build.platform "amd64" must be a linux platform`)
+	assertContainsError(t, r, `build.args: "BAD-NAME" is not a valid build arg name`)
+	assertContainsError(t, r, "build.secrets[0]: secret \"src=.npmrc\" has no id")
+	assertContainsError(t, r, "build.cache_to[0]")
+}
+
+func TestDockerfileResolvedAgainstKyperFileDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM ruby"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kf := validKyperFile()
+	kf.Dir = dir
+	r := Validate(kf, true)
+	if !r.Valid {
+		t.Errorf("expected valid, got errors: %v", r.Errors)
+	}
+}
+
+func TestSmokeChecksValid(t *testing.T) {
+	kf := validKyperFile()
+	kf.Smoke = []config.SmokeCheck{
+		{Name: "home", Path: "/", Status: 200, Body: `(?i)welcome`, Timeout: 5},
+		{Path: "/up"},
+	}
+	r := Validate(kf, false)
+	if !r.Valid {
+		t.Errorf("expected valid, got errors: %v", r.Errors)
+	}
+}
+
+func TestSmokeChecksInvalid(t *testing.T) {
+	kf := validKyperFile()
+	kf.Smoke = []config.SmokeCheck{
+		{Name: "home", Path: "home"},
+		{Path: "/", Status: 42},
+		{Path: "/", Body: "(unclosed"},
+		{Path: "/", Timeout: -1},
+	}
+	r := Validate(kf, false)
+	assertContainsError(t, r, `smoke "home": path must start with /`)
+	assertContainsError(t, r, "smoke[1]: status must be a valid HTTP status code")
+	assertContainsError(t, r, "smoke[2]: body is not a valid regular expression")
+	assertContainsError(t, r, "smoke[3]: timeout must be a positive integer")
+}
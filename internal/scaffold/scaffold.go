@@ -0,0 +1,150 @@
+// Package scaffold renders the production Dockerfile and .dockerignore that
+// kyper init offers for a detected stack.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Data fills in a Dockerfile template.
+type Data struct {
+	// Template is the template name returned by TemplateFor.
+	Template string
+	// Version is the language version for the base image; DefaultVersion
+	// applies when it's empty.
+	Version string
+	// Command is the CMD, normally processes.web; DefaultCommand applies
+	// when it's empty.
+	Command string
+	// PackageManager is npm, yarn or pnpm, from NodePackageManager; the
+	// node template falls back to `npm install` when it's empty.
+	PackageManager string
+	// Prisma adds `prisma generate`, OpenSSL for its query engine, and keeps
+	// the prisma CLI in the image.
+	Prisma bool
+	// Postgres and MySQL add the client libraries for those deps.
+	Postgres bool
+	MySQL    bool
+}
+
+// TemplateFor picks the template for detected stacks, or "" if none fits.
+// Framework stacks win over the Node frameworks and Prisma, which share the
+// node template.
+func TemplateFor(stacks []string) string {
+	node := false
+	for _, s := range stacks {
+		switch s {
+		case "rails", "django", "laravel", "go":
+			return s
+		case "next", "nuxt", "sveltekit", "remix", "astro", "nest", "express", "koa", "prisma":
+			node = true
+		}
+	}
+	if node {
+		return "node"
+	}
+	return ""
+}
+
+// NodePackageManager returns the package manager whose lockfile is in dir:
+// pnpm, yarn or npm, or "" when there's no lockfile.
+func NodePackageManager(dir string) string {
+	for _, lock := range []struct{ file, manager string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"package-lock.json", "npm"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			return lock.manager
+		}
+	}
+	return ""
+}
+
+// Language returns the language a template builds from.
+func Language(tmpl string) string {
+	switch tmpl {
+	case "rails":
+		return "ruby"
+	case "django":
+		return "python"
+	case "laravel":
+		return "php"
+	}
+	return tmpl
+}
+
+// DefaultVersion is the language version a template uses when none is
+// detected.
+func DefaultVersion(tmpl string) string {
+	switch tmpl {
+	case "rails":
+		return "3.3"
+	case "django":
+		return "3.12"
+	case "laravel":
+		return "8.3"
+	case "go":
+		return "1.23"
+	case "node":
+		return "20"
+	}
+	return ""
+}
+
+// DefaultCommand is the CMD a template uses when there's no web process.
+func DefaultCommand(tmpl string) string {
+	switch tmpl {
+	case "rails":
+		return "./bin/rails server -b 0.0.0.0 -p $PORT"
+	case "django":
+		return "gunicorn --bind 0.0.0.0:$PORT config.wsgi"
+	case "laravel":
+		return "php artisan serve --host=0.0.0.0 --port=$PORT"
+	case "go":
+		return "/app/server"
+	case "node":
+		return "npm start"
+	}
+	return ""
+}
+
+// Dockerfile renders the Dockerfile template for d.
+func Dockerfile(d Data) (string, error) {
+	if d.Version == "" {
+		d.Version = DefaultVersion(d.Template)
+	}
+	if d.Command == "" {
+		d.Command = DefaultCommand(d.Template)
+	}
+	return render(d.Template+".Dockerfile", d)
+}
+
+// Dockerignore renders the .dockerignore template for tmpl.
+func Dockerignore(tmpl string) (string, error) {
+	return render(tmpl+".dockerignore", nil)
+}
+
+func render(name string, data interface{}) (string, error) {
+	src, err := templates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("no template %q", name)
+	}
+	t, err := template.New(name).Parse(string(src))
+	if err != nil {
+		return "", fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,138 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+	"github.com/bitfootco/kyper-cli/internal/kyperfile"
+)
+
+func TestTemplateFor(t *testing.T) {
+	tests := []struct {
+		stacks []string
+		want   string
+	}{
+		{[]string{"rails"}, "rails"},
+		{[]string{"next", "prisma"}, "node"},
+		{[]string{"prisma"}, "node"},
+		{[]string{"sveltekit"}, "node"},
+		{[]string{"fastapi"}, ""},
+		{[]string{"express", "go"}, "go"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := TemplateFor(tt.stacks); got != tt.want {
+			t.Errorf("TemplateFor(%v) = %q, want %q", tt.stacks, got, tt.want)
+		}
+	}
+}
+
+// Every generated Dockerfile should pass kyper check's lints cleanly.
+func TestTemplatesPassLint(t *testing.T) {
+	for _, tmpl := range []string{"rails", "django", "laravel", "go", "node"} {
+		t.Run(tmpl, func(t *testing.T) {
+			dockerfile, err := Dockerfile(Data{Template: tmpl, Prisma: tmpl == "node", Postgres: true})
+			if err != nil {
+				t.Fatalf("Dockerfile failed: %v", err)
+			}
+			dockerignore, err := Dockerignore(tmpl)
+			if err != nil {
+				t.Fatalf("Dockerignore failed: %v", err)
+			}
+			if !strings.Contains(dockerignore, ".env") {
+				t.Error(".dockerignore should exclude .env files")
+			}
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(dockerignore), 0644); err != nil {
+				t.Fatal(err)
+			}
+			kf := &config.KyperFile{
+				Docker:    config.DockerConfig{Dockerfile: "./Dockerfile"},
+				Processes: map[string]string{"web": DefaultCommand(tmpl)},
+				Dir:       dir,
+			}
+			r := &kyperfile.ValidationResult{Valid: true}
+			kyperfile.LintDockerfile(kf, r)
+			if !r.Valid || len(r.Warnings) > 0 {
+				t.Errorf("lint findings:\nerrors %v\nwarnings %v\n%s", r.Errors, r.Warnings, dockerfile)
+			}
+		})
+	}
+}
+
+func TestDockerfileUsesVersionAndCommand(t *testing.T) {
+	out, err := Dockerfile(Data{Template: "rails", Version: "3.4.1", Command: "bundle exec puma -p $PORT"})
+	if err != nil {
+		t.Fatalf("Dockerfile failed: %v", err)
+	}
+	for _, want := range []string{"ARG RUBY_VERSION=3.4.1", "CMD bundle exec puma -p $PORT", "USER rails", "EXPOSE $PORT"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dockerfile missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "libpq") {
+		t.Error("postgres libraries should only be added for the postgres dep")
+	}
+
+	out, err = Dockerfile(Data{Template: "node"})
+	if err != nil {
+		t.Fatalf("Dockerfile failed: %v", err)
+	}
+	if !strings.Contains(out, "ARG NODE_VERSION=20\n") || !strings.Contains(out, "npm prune --omit=dev") || strings.Contains(out, "prisma") {
+		t.Errorf("unexpected node Dockerfile:\n%s", out)
+	}
+
+	out, err = Dockerfile(Data{Template: "node", Prisma: true, PackageManager: "npm"})
+	if err != nil {
+		t.Fatalf("Dockerfile failed: %v", err)
+	}
+	if n := strings.Count(out, "install --no-install-recommends -y openssl"); n != 2 {
+		t.Errorf("expected openssl in both stages for prisma, got %d:\n%s", n, out)
+	}
+
+	if _, err := Dockerfile(Data{Template: "cobol"}); err == nil {
+		t.Error("expected error for unknown template")
+	}
+}
+
+func TestNodePackageManager(t *testing.T) {
+	tests := []struct {
+		lockfile string
+		want     string
+		install  string
+	}{
+		{"pnpm-lock.yaml", "pnpm", "pnpm install --frozen-lockfile"},
+		{"yarn.lock", "yarn", "yarn install --frozen-lockfile"},
+		{"package-lock.json", "npm", "npm ci"},
+		{"", "", "npm install"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if tt.lockfile != "" {
+			if err := os.WriteFile(filepath.Join(dir, tt.lockfile), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got := NodePackageManager(dir)
+		if got != tt.want {
+			t.Errorf("NodePackageManager with %q = %q, want %q", tt.lockfile, got, tt.want)
+		}
+		out, err := Dockerfile(Data{Template: "node", PackageManager: got})
+		if err != nil {
+			t.Fatalf("Dockerfile failed: %v", err)
+		}
+		if !strings.Contains(out, "RUN "+tt.install+"\n") && !strings.Contains(out, "&& "+tt.install+"\n") {
+			t.Errorf("expected %q for %q:\n%s", tt.install, tt.lockfile, out)
+		}
+		if tt.want != "npm" && strings.Contains(out, "npm ci") {
+			t.Errorf("npm ci needs package-lock.json:\n%s", out)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package scan runs a locally installed vulnerability scanner (trivy or
+// grype) against an image and applies Kyper's severity gate.
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Scanners lists the supported scanners in auto-detection order.
+var Scanners = []string{"trivy", "grype"}
+
+// Severities in increasing order.
+var Severities = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// DefaultThreshold is the severity at which Kyper's build pipeline rejects
+// an image.
+const DefaultThreshold = "CRITICAL"
+
+// Finding is one vulnerability in one package.
+type Finding struct {
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Package   string `json:"package"`
+	Installed string `json:"installed"`
+	FixedIn   string `json:"fixed_in,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Report is the result of scanning one image.
+type Report struct {
+	Scanner  string
+	Findings []Finding
+}
+
+// Rank returns a severity's position in Severities, treating anything
+// unrecognised as UNKNOWN.
+func Rank(severity string) int {
+	for i, s := range Severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return 0
+}
+
+// ValidSeverity reports whether s names a severity.
+func ValidSeverity(s string) bool {
+	for _, sev := range Severities {
+		if strings.EqualFold(sev, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts returns the number of findings per severity.
+func (r *Report) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, s := range Severities {
+		counts[s] = 0
+	}
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// AtOrAbove returns the findings at or above threshold, most severe first.
+func (r *Report) AtOrAbove(threshold string) []Finding {
+	floor := Rank(threshold)
+	out := []Finding{}
+	for _, f := range r.Findings {
+		if Rank(f.Severity) >= floor {
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if Rank(out[i].Severity) != Rank(out[j].Severity) {
+			return Rank(out[i].Severity) > Rank(out[j].Severity)
+		}
+		if out[i].Package != out[j].Package {
+			return out[i].Package < out[j].Package
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Detect returns the scanner named by preferred, or the first of Scanners
+// found in $PATH when preferred is empty.
+func Detect(preferred string) (string, error) {
+	if preferred != "" {
+		if preferred != "trivy" && preferred != "grype" {
+			return "", fmt.Errorf("unknown scanner %q — use trivy or grype", preferred)
+		}
+		if _, err := exec.LookPath(preferred); err != nil {
+			return "", fmt.Errorf("scanner %q not found in $PATH", preferred)
+		}
+		return preferred, nil
+	}
+	for _, name := range Scanners {
+		if _, err := exec.LookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no vulnerability scanner found in $PATH — install trivy (https://trivy.dev) or grype (https://github.com/anchore/grype)")
+}
+
+// Args returns the command line that scans image from engine's local image
+// store with scanner, producing JSON on stdout.
+func Args(scanner, engine, image string) ([]string, error) {
+	switch scanner {
+	case "trivy":
+		src := engine
+		if engine == "nerdctl" {
+			src = "containerd"
+		}
+		return []string{"image", "--format", "json", "--quiet", "--image-src", src, image}, nil
+	case "grype":
+		if engine == "nerdctl" {
+			return nil, fmt.Errorf("grype can't read nerdctl images — install trivy or use --builder docker")
+		}
+		return []string{engine + ":" + image, "--output", "json", "--quiet"}, nil
+	}
+	return nil, fmt.Errorf("unknown scanner %q", scanner)
+}
+
+// Run scans image and parses the scanner's report.
+func Run(scanner, engine, image string) (*Report, error) {
+	args, err := Args(scanner, engine, image)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(scanner, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s failed: %s", scanner, msg)
+	}
+
+	var findings []Finding
+	if scanner == "trivy" {
+		findings, err = ParseTrivy(stdout.Bytes())
+	} else {
+		findings, err = ParseGrype(stdout.Bytes())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s report: %w", scanner, err)
+	}
+	return &Report{Scanner: scanner, Findings: findings}, nil
+}
+
+// ParseTrivy reads the findings from `trivy image --format json`.
+func ParseTrivy(data []byte) ([]Finding, error) {
+	var report struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+				Title            string
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	findings := []Finding{}
+	for _, res := range report.Results {
+		for _, v := range res.Vulnerabilities {
+			findings = append(findings, Finding{
+				ID:        v.VulnerabilityID,
+				Severity:  normalizeSeverity(v.Severity),
+				Package:   v.PkgName,
+				Installed: v.InstalledVersion,
+				FixedIn:   v.FixedVersion,
+				Title:     v.Title,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// ParseGrype reads the findings from `grype -o json`.
+func ParseGrype(data []byte) ([]Finding, error) {
+	var report struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID          string `json:"id"`
+				Severity    string `json:"severity"`
+				Description string `json:"description"`
+				Fix         struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	findings := []Finding{}
+	for _, m := range report.Matches {
+		findings = append(findings, Finding{
+			ID:        m.Vulnerability.ID,
+			Severity:  normalizeSeverity(m.Vulnerability.Severity),
+			Package:   m.Artifact.Name,
+			Installed: m.Artifact.Version,
+			FixedIn:   strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Title:     m.Vulnerability.Description,
+		})
+	}
+	return findings, nil
+}
+
+// normalizeSeverity maps scanner severities onto Severities; grype's
+// "Negligible" counts as LOW.
+func normalizeSeverity(s string) string {
+	s = strings.ToUpper(s)
+	if s == "NEGLIGIBLE" {
+		return "LOW"
+	}
+	if !ValidSeverity(s) {
+		return "UNKNOWN"
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+const trivyJSON = `{
+  "Results": [
+    {"Target": "app (debian 12.5)", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.0.11", "FixedVersion": "3.0.13", "Severity": "CRITICAL", "Title": "bad"},
+      {"VulnerabilityID": "CVE-2024-0002", "PkgName": "zlib", "InstalledVersion": "1.2.13", "Severity": "LOW"}
+    ]},
+    {"Target": "Gemfile.lock"}
+  ]
+}`
+
+const grypeJSON = `{
+  "matches": [
+    {"vulnerability": {"id": "GHSA-xxxx", "severity": "High", "fix": {"versions": ["7.1.3", "7.0.8"]}}, "artifact": {"name": "rails", "version": "7.1.2"}},
+    {"vulnerability": {"id": "CVE-2023-9999", "severity": "Negligible"}, "artifact": {"name": "tar", "version": "1.34"}}
+  ]
+}`
+
+func TestParseTrivy(t *testing.T) {
+	findings, err := ParseTrivy([]byte(trivyJSON))
+	if err != nil {
+		t.Fatalf("ParseTrivy failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings", len(findings))
+	}
+	want := Finding{ID: "CVE-2024-0001", Severity: "CRITICAL", Package: "openssl", Installed: "3.0.11", FixedIn: "3.0.13", Title: "bad"}
+	if findings[0] != want {
+		t.Errorf("finding = %+v, want %+v", findings[0], want)
+	}
+}
+
+func TestParseGrype(t *testing.T) {
+	findings, err := ParseGrype([]byte(grypeJSON))
+	if err != nil {
+		t.Fatalf("ParseGrype failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings", len(findings))
+	}
+	if f := findings[0]; f.Severity != "HIGH" || f.Package != "rails" || f.FixedIn != "7.1.3, 7.0.8" {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if findings[1].Severity != "LOW" {
+		t.Errorf("negligible should count as LOW, got %q", findings[1].Severity)
+	}
+}
+
+func TestGate(t *testing.T) {
+	r := &Report{Findings: []Finding{
+		{ID: "a", Severity: "LOW", Package: "z"},
+		{ID: "b", Severity: "CRITICAL", Package: "y"},
+		{ID: "c", Severity: "HIGH", Package: "x"},
+	}}
+	got := r.AtOrAbove("high")
+	if len(got) != 2 || got[0].ID != "b" || got[1].ID != "c" {
+		t.Errorf("AtOrAbove(high) = %+v", got)
+	}
+	if n := len(r.AtOrAbove(DefaultThreshold)); n != 1 {
+		t.Errorf("default gate should only count critical findings, got %d", n)
+	}
+	counts := r.Counts()
+	if counts["LOW"] != 1 || counts["MEDIUM"] != 0 || counts["CRITICAL"] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	args, err := Args("trivy", "nerdctl", "kyper-local/app:1.0.0")
+	if err != nil || strings.Join(args, " ") != "image --format json --quiet --image-src containerd kyper-local/app:1.0.0" {
+		t.Errorf("trivy args = %v, %v", args, err)
+	}
+	args, err = Args("grype", "podman", "kyper-local/app:1.0.0")
+	if err != nil || args[0] != "podman:kyper-local/app:1.0.0" {
+		t.Errorf("grype args = %v, %v", args, err)
+	}
+	if _, err := Args("grype", "nerdctl", "img"); err == nil {
+		t.Error("expected grype to reject nerdctl images")
+	}
+	if _, err := Detect("clair"); err == nil || !strings.Contains(err.Error(), "unknown scanner") {
+		t.Errorf("expected unknown scanner error, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package smoke runs the HTTP checks from kyper.yml's smoke section against a
+// live deployment and reports the results.
+package smoke
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+const (
+	// DefaultStatus is the expected status when a check doesn't set one.
+	DefaultStatus = http.StatusOK
+	// DefaultTimeout applies when neither the check nor healthcheck.timeout
+	// sets one.
+	DefaultTimeout = 10 * time.Second
+
+	// maxBodyBytes caps how much of a response body is matched against Body.
+	maxBodyBytes = 1 << 20
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Checks returns kf's smoke checks. Without a smoke section, healthcheck.path
+// is the only check; with neither, there is nothing to run.
+func Checks(kf *config.KyperFile) []config.SmokeCheck {
+	if len(kf.Smoke) > 0 {
+		return kf.Smoke
+	}
+	if kf.Healthcheck.Path != "" {
+		return []config.SmokeCheck{{
+			Name:    "healthcheck",
+			Path:    kf.Healthcheck.Path,
+			Timeout: kf.Healthcheck.Timeout,
+		}}
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client to pass to Run. It doesn't follow
+// redirects, so a check that expects a 301 or 302 sees the response the app
+// actually sent.
+func NewClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run executes checks in order against baseURL.
+func Run(client *http.Client, baseURL string, checks []config.SmokeCheck) []Result {
+	results := make([]Result, len(checks))
+	for i, c := range checks {
+		results[i] = runCheck(client, baseURL, c)
+	}
+	return results
+}
+
+// Failed counts the results that didn't pass.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if !r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+func runCheck(client *http.Client, baseURL string, c config.SmokeCheck) Result {
+	res := Result{Name: c.Name, URL: strings.TrimRight(baseURL, "/") + c.Path}
+	if res.Name == "" {
+		res.Name = "GET " + c.Path
+	}
+
+	want := c.Status
+	if want == 0 {
+		want = DefaultStatus
+	}
+	timeout := DefaultTimeout
+	if c.Timeout > 0 {
+		timeout = time.Duration(c.Timeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { res.DurationMS = time.Since(start).Milliseconds() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, res.URL, nil)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			res.Error = fmt.Sprintf("timed out after %s", timeout)
+		} else {
+			res.Error = err.Error()
+		}
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	res.Status = resp.StatusCode
+
+	if resp.StatusCode != want {
+		res.Error = fmt.Sprintf("expected status %d, got %d", want, resp.StatusCode)
+		return res
+	}
+	if c.Body != "" {
+		re, err := regexp.Compile(c.Body)
+		if err != nil {
+			res.Error = fmt.Sprintf("invalid body pattern: %v", err)
+			return res
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			res.Error = fmt.Sprintf("reading body: %v", err)
+			return res
+		}
+		if !re.Match(body) {
+			res.Error = fmt.Sprintf("body does not match %q", c.Body)
+			return res
+		}
+	}
+
+	res.Passed = true
+	return res
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as a JUnit XML report with one suite named
+// suite, for CI systems that render test results.
+func WriteJUnit(w io.Writer, suite string, results []Result) error {
+	s := junitSuite{Name: suite, Tests: len(results), Failures: Failed(results)}
+	var total int64
+	for _, r := range results {
+		total += r.DurationMS
+		tc := junitCase{Name: r.Name, ClassName: suite, Time: seconds(r.DurationMS)}
+		if !r.Passed {
+			tc.Failure = &junitFailure{Message: r.Error, Text: "GET " + r.URL + ": " + r.Error}
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+	s.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{s}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
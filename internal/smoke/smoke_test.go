@@ -0,0 +1,148 @@
+package smoke
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+func TestChecksDefaultsToHealthcheck(t *testing.T) {
+	kf := &config.KyperFile{Healthcheck: config.HealthcheckConfig{Path: "/up", Timeout: 3}}
+	checks := Checks(kf)
+	if len(checks) != 1 || checks[0].Path != "/up" || checks[0].Timeout != 3 {
+		t.Errorf("expected healthcheck as the default check, got %+v", checks)
+	}
+
+	kf.Smoke = []config.SmokeCheck{{Path: "/"}, {Path: "/pricing"}}
+	if checks := Checks(kf); len(checks) != 2 {
+		t.Errorf("expected smoke section to replace the default, got %+v", checks)
+	}
+
+	if checks := Checks(&config.KyperFile{}); len(checks) != 0 {
+		t.Errorf("expected no checks, got %+v", checks)
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte("<h1>Welcome to Invoice Hero</h1>"))
+		case "/admin":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			_, _ = w.Write([]byte("Sign in"))
+		case "/slow":
+			time.Sleep(1500 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	checks := []config.SmokeCheck{
+		{Name: "home", Path: "/", Body: "(?i)welcome"},
+		{Path: "/admin", Status: 302},
+		{Name: "wrong body", Path: "/", Body: "Dashboard"},
+		{Path: "/broken"},
+		{Path: "/slow", Timeout: 1},
+	}
+	results := Run(NewClient(), srv.URL+"/", checks)
+
+	tests := []struct {
+		name    string
+		passed  bool
+		wantErr string
+	}{
+		{"home", true, ""},
+		{"GET /admin", true, ""},
+		{"wrong body", false, `body does not match "Dashboard"`},
+		{"GET /broken", false, "expected status 200, got 500"},
+		{"GET /slow", false, "timed out after 1s"},
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Name != tt.name || r.Passed != tt.passed || !strings.Contains(r.Error, tt.wantErr) {
+			t.Errorf("result %d = %+v, want name %q passed %v error %q", i, r, tt.name, tt.passed, tt.wantErr)
+		}
+	}
+	if results[0].URL != srv.URL+"/" {
+		t.Errorf("expected URL without doubled slash, got %q", results[0].URL)
+	}
+	if got := Failed(results); got != 3 {
+		t.Errorf("Failed() = %d, want 3", got)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []Result{
+		{Name: "home", URL: "https://example.test/", Status: 200, DurationMS: 120, Passed: true},
+		{Name: "GET /broken", URL: "https://example.test/broken", Status: 500, DurationMS: 80, Error: "expected status 200, got 500"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "kyper smoke", results); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+
+	var parsed junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	s := parsed.Suites[0]
+	if s.Tests != 2 || s.Failures != 1 || s.Time != "0.200" {
+		t.Errorf("unexpected suite: %+v", s)
+	}
+	if s.Cases[0].Failure != nil {
+		t.Error("passing case should have no failure")
+	}
+	if s.Cases[1].Failure == nil || s.Cases[1].Failure.Message != "expected status 200, got 500" {
+		t.Errorf("unexpected failure: %+v", s.Cases[1].Failure)
+	}
+}
+
+func TestWaitHealthy(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	check := config.SmokeCheck{Name: "healthcheck", Path: "/up"}
+	took, res, err := WaitHealthy(srv.Client(), srv.URL, check, WaitOptions{Interval: 10 * time.Millisecond, Deadline: time.Second})
+	if err != nil || !res.Passed || calls != 3 {
+		t.Fatalf("expected healthy on the third attempt, got %+v, %v after %d calls", res, err, calls)
+	}
+	if took < 20*time.Millisecond {
+		t.Errorf("time to healthy %s should include the polling interval", took)
+	}
+}
+
+func TestWaitHealthyGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	check := config.SmokeCheck{Path: "/up"}
+	_, _, err := WaitHealthy(srv.Client(), srv.URL, check, WaitOptions{Interval: 10 * time.Millisecond, Deadline: 50 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "expected status 200, got 500") {
+		t.Errorf("expected deadline error with the last failure, got %v", err)
+	}
+
+	exited := fmt.Errorf("web exited with status 1")
+	_, _, err = WaitHealthy(srv.Client(), srv.URL, check, WaitOptions{Deadline: time.Minute, Alive: func() error { return exited }})
+	if err != exited {
+		t.Errorf("expected Alive error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package smoke
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bitfootco/kyper-cli/internal/config"
+)
+
+// DefaultInterval applies when healthcheck.interval isn't set.
+const DefaultInterval = 10 * time.Second
+
+// WaitOptions controls WaitHealthy.
+type WaitOptions struct {
+	// Interval is the pause between attempts.
+	Interval time.Duration
+	// Deadline is how long to keep trying.
+	Deadline time.Duration
+	// Alive, if set, is called before each attempt; an error stops waiting
+	// early, e.g. when the container has exited.
+	Alive func() error
+}
+
+// WaitHealthy polls check against baseURL until it passes, the deadline
+// passes or Alive fails. It returns how long the check took to pass and the
+// last result.
+func WaitHealthy(client *http.Client, baseURL string, check config.SmokeCheck, opts WaitOptions) (time.Duration, Result, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	start := time.Now()
+	for {
+		if opts.Alive != nil {
+			if err := opts.Alive(); err != nil {
+				return time.Since(start), Result{}, err
+			}
+		}
+		res := runCheck(client, baseURL, check)
+		if res.Passed {
+			return time.Since(start), res, nil
+		}
+		if time.Since(start)+interval > opts.Deadline {
+			return time.Since(start), res, fmt.Errorf("not healthy after %s: %s", time.Since(start).Round(time.Second), res.Error)
+		}
+		time.Sleep(interval)
+	}
+}